@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/backstabslash/goccc/usage"
+)
+
+// defaultArchiveDir is where `goccc archive` keeps its copies when no
+// -archive-dir is given.
+func defaultArchiveDir(baseDir string) string {
+	return filepath.Join(baseDir, "goccc-archive")
+}
+
+// runArchive implements `goccc archive` and returns the exit status.
+func runArchive(args []string) int {
+	fset := flag.NewFlagSet("archive", flag.ExitOnError)
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: cannot determine home directory: %v\n", err)
+		return 1
+	}
+	baseDir := fset.String("base-dir", filepath.Join(homeDir, ".claude"), "Base directory for Claude Code data")
+	archiveDir := fset.String("archive-dir", "", "Archive directory (default <base-dir>/goccc-archive)")
+	fset.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: goccc archive [flags]\n\n")
+		fmt.Fprintf(os.Stderr, "Copies new and changed JSONL logs, subagent transcripts included, into a\n")
+		fmt.Fprintf(os.Stderr, "gzip-compressed archive that outlives Claude Code's log cleanup.\n")
+		fmt.Fprintf(os.Stderr, "Run it regularly, and report on it with goccc -include-archive.\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		fset.PrintDefaults()
+	}
+	_ = fset.Parse(args)
+	if fset.NArg() > 0 {
+		fset.Usage()
+		return 2
+	}
+	if *archiveDir == "" {
+		*archiveDir = defaultArchiveDir(*baseDir)
+	}
+
+	projectsDir := filepath.Join(*baseDir, "projects")
+	if info, err := os.Stat(projectsDir); err != nil || !info.IsDir() {
+		fmt.Fprintf(os.Stderr, "Error: no projects directory found at %s\n", projectsDir)
+		return 1
+	}
+
+	stats, err := usage.ArchiveLogs(projectsDir, *archiveDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Archived %d new and %d changed log files to %s (%d unchanged)\n",
+		stats.Added, stats.Updated, *archiveDir, stats.Unchanged)
+	for _, err := range stats.Errors {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if len(stats.Errors) > 0 {
+		fmt.Fprintf(os.Stderr, "goccc: %d files could not be archived\n", len(stats.Errors))
+		return 1
+	}
+	return 0
+}
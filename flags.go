@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/backstabslash/goccc/usage"
+)
+
+// sourcesFlag collects repeated -base-dir values, each a path or label=path.
+type sourcesFlag []usage.Source
+
+func (f *sourcesFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	dirs := make([]string, len(*f))
+	for i, s := range *f {
+		dirs[i] = s.BaseDir
+	}
+	return strings.Join(dirs, ", ")
+}
+
+func (f *sourcesFlag) Set(v string) error {
+	src, err := parseSource(v)
+	if err != nil {
+		return err
+	}
+	for _, s := range *f {
+		if s.Label == src.Label {
+			return fmt.Errorf("duplicate source label %q (name each directory with label=path)", src.Label)
+		}
+	}
+	*f = append(*f, src)
+	return nil
+}
+
+// parseSource parses a -base-dir value. A label is whatever precedes the
+// first '=', as long as it contains no path separator; without one, the
+// directory's base name is used.
+func parseSource(v string) (usage.Source, error) {
+	label, dir, ok := strings.Cut(v, "=")
+	if !ok || label == "" || strings.ContainsAny(label, `/\`) {
+		label, dir = "", v
+	}
+	if dir == "" {
+		return usage.Source{}, fmt.Errorf("empty directory in %q", v)
+	}
+	if label == "" {
+		label = filepath.Base(filepath.Clean(dir))
+	}
+	return usage.Source{Label: label, BaseDir: dir}, nil
+}
+
+// filesFlag collects repeated -f values.
+type filesFlag []string
+
+func (f *filesFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return fmt.Sprint([]string(*f))
+}
+
+func (f *filesFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		in    string
+		label string
+		dir   string
+	}{
+		{"/home/me/.claude", ".claude", "/home/me/.claude"},
+		{"/home/me/.claude/", ".claude", "/home/me/.claude/"},
+		{"work=/home/me/.claude-work", "work", "/home/me/.claude-work"},
+		{"/mnt/a=b/.claude", ".claude", "/mnt/a=b/.claude"},
+		{"=/srv/claude", "claude", "=/srv/claude"},
+	}
+	for _, tt := range tests {
+		src, err := parseSource(tt.in)
+		if err != nil {
+			t.Errorf("parseSource(%q): %v", tt.in, err)
+			continue
+		}
+		if src.Label != tt.label || src.BaseDir != tt.dir {
+			t.Errorf("parseSource(%q) = %q, %q; want %q, %q", tt.in, src.Label, src.BaseDir, tt.label, tt.dir)
+		}
+	}
+	if _, err := parseSource("work="); err == nil {
+		t.Error("parseSource(\"work=\") should fail")
+	}
+}
+
+func TestSourcesFlag_DuplicateLabel(t *testing.T) {
+	var f sourcesFlag
+	if err := f.Set("/a/.claude"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set("/b/.claude"); err == nil {
+		t.Error("second .claude without a label should be rejected")
+	}
+	if err := f.Set("b=/b/.claude"); err != nil {
+		t.Errorf("labeled duplicate: %v", err)
+	}
+	if len(f) != 2 {
+		t.Errorf("len(sources) = %d, want 2", len(f))
+	}
+}
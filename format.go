@@ -1,13 +1,16 @@
 package main
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/backstabslash/goccc/usage"
 	"github.com/fatih/color"
 )
 
@@ -87,13 +90,331 @@ func shortProject(slug string) string {
 	return s
 }
 
+// shortPath abbreviates a project's working directory for display: the home
+// directory prefix (/Users/<user>, /home/<user>, C:\Users\<user>) is dropped
+// and separators become slashes.
+func shortPath(cwd string) string {
+	p := strings.TrimRight(strings.ReplaceAll(cwd, `\`, "/"), "/")
+	rest := p
+	if len(rest) >= 2 && rest[1] == ':' {
+		rest = rest[2:]
+	}
+	parts := strings.Split(strings.TrimPrefix(rest, "/"), "/")
+	if len(parts) >= 2 && (strings.EqualFold(parts[0], "Users") || parts[0] == "home") {
+		if len(parts) == 2 {
+			return "~"
+		}
+		p = strings.Join(parts[2:], "/")
+	}
+	if len(p) > 40 {
+		p = "..." + p[len(p)-37:]
+	}
+	return p
+}
+
+// projectName is the display name of a project: its real working directory
+// when the logs record it, else a best guess from the slug.
+func projectName(data *usage.ParseResult, slug string) string {
+	if cwd := data.ProjectPaths[slug]; cwd != "" {
+		return shortPath(cwd)
+	}
+	return shortProject(slug)
+}
+
+// shortSession abbreviates a session UUID to its first block.
+func shortSession(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// sessionModels lists a session's models by descending cost.
+func sessionModels(s *usage.SessionUsage) []string {
+	var sorted []modelEntry
+	for name, b := range s.Models {
+		sorted = append(sorted, modelEntry{name, b})
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].bucket.Cost > sorted[j].bucket.Cost })
+	names := make([]string, len(sorted))
+	for i, m := range sorted {
+		names[i] = usage.ModelName(m.name)
+	}
+	return names
+}
+
+func branchName(branch string) string {
+	if branch == "" {
+		return "(none)"
+	}
+	return branch
+}
+
+func agentTypeName(agentType string) string {
+	if agentType == "" {
+		return "(unknown)"
+	}
+	return agentType
+}
+
+// threadSplit returns main-thread and subagent cost, and the subagent share
+// of the total.
+func threadSplit(threads map[string]*usage.Bucket) (mainCost, subCost, share float64) {
+	if b, ok := threads[usage.ThreadMain]; ok {
+		mainCost = b.Cost
+	}
+	if b, ok := threads[usage.ThreadSubagent]; ok {
+		subCost = b.Cost
+	}
+	if total := mainCost + subCost; total > 0 {
+		share = subCost / total
+	}
+	return
+}
+
+type projTotal struct {
+	slug  string
+	total float64
+}
+
+func sortedThreadProjects(data *usage.ParseResult, topN int) []projTotal {
+	var projects []projTotal
+	for slug, threads := range data.ThreadUsage {
+		mainCost, subCost, _ := threadSplit(threads)
+		projects = append(projects, projTotal{slug, mainCost + subCost})
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].total > projects[j].total })
+	if topN > 0 && len(projects) > topN {
+		projects = projects[:topN]
+	}
+	return projects
+}
+
+func toolName(name string) string {
+	if name == "" {
+		return "(no tool calls)"
+	}
+	return name
+}
+
+type toolEntry struct {
+	name  string
+	usage *usage.ToolUsage
+}
+
+func sortedToolEntries(m map[string]*usage.ToolUsage) []toolEntry {
+	var entries []toolEntry
+	for name, u := range m {
+		entries = append(entries, toolEntry{name, u})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].usage.Cost != entries[j].usage.Cost {
+			return entries[i].usage.Cost > entries[j].usage.Cost
+		}
+		return entries[i].name < entries[j].name
+	})
+	return entries
+}
+
+func versionName(v string) string {
+	if v == "" {
+		return "(unknown)"
+	}
+	return v
+}
+
+// compareVersions orders dotted version strings numerically, so 2.1.10
+// sorts after 2.1.9. Non-numeric parts compare as strings.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil && an != bn:
+			return cmp.Compare(an, bn)
+		case (aErr != nil || bErr != nil) && as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
+}
+
+func sortedVersions(data *usage.ParseResult) []modelEntry {
+	var versions []modelEntry
+	for v, b := range data.VersionUsage {
+		versions = append(versions, modelEntry{v, b})
+	}
+	sort.Slice(versions, func(i, j int) bool { return compareVersions(versions[i].name, versions[j].name) > 0 })
+	return versions
+}
+
+func tierName(tier string) string {
+	if tier == "" {
+		return "(unreported)"
+	}
+	return tier
+}
+
+func sortedTiers(data *usage.ParseResult) []modelEntry {
+	var tiers []modelEntry
+	for t, b := range data.TierUsage {
+		tiers = append(tiers, modelEntry{t, b})
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].bucket.Cost > tiers[j].bucket.Cost })
+	return tiers
+}
+
+// sortedSources lists every source read, including those that contributed
+// nothing, most expensive first.
+func sortedSources(data *usage.ParseResult) []modelEntry {
+	var sources []modelEntry
+	for _, src := range data.Sources {
+		b := data.SourceUsage[src.Label]
+		if b == nil {
+			b = &usage.Bucket{}
+		}
+		sources = append(sources, modelEntry{src.Label, b})
+	}
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].bucket.Cost > sources[j].bucket.Cost })
+	return sources
+}
+
+func sortedUnmatchedModels(data *usage.ParseResult) []modelEntry {
+	var models []modelEntry
+	for m, b := range data.Diagnostics.UnmatchedModels {
+		models = append(models, modelEntry{m, b})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].bucket.Cost > models[j].bucket.Cost })
+	return models
+}
+
+type limitWeek struct {
+	week string
+	hits int
+}
+
+// sortedLimitWeeks lists limit hits per week, newest first.
+func sortedLimitWeeks(data *usage.ParseResult, week usage.WeekOptions) []limitWeek {
+	var weeks []limitWeek
+	for w, n := range data.LimitsByWeek(week) {
+		weeks = append(weeks, limitWeek{w, n})
+	}
+	sort.Slice(weeks, func(i, j int) bool { return weeks[i].week > weeks[j].week })
+	return weeks
+}
+
+func avgCost(b *usage.Bucket) float64 {
+	if b.Requests == 0 {
+		return 0
+	}
+	return b.Cost / float64(b.Requests)
+}
+
+// location returns loc, or the local zone when it is nil, as usage.Options does.
+func location(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.Local
+	}
+	return loc
+}
+
+func isMidnight(t time.Time) bool {
+	h, m, s := t.Clock()
+	return h == 0 && m == 0 && s == 0 && t.Nanosecond() == 0
+}
+
+// fmtRange renders a report's time window for the header. The exclusive
+// upper bound is shown as the last day it includes when it falls on midnight.
+func fmtRange(since, until time.Time) (from, to string) {
+	from, to = "beginning", "now"
+	if !since.IsZero() {
+		from = since.Format("2006-01-02")
+		if !isMidnight(since) {
+			from = since.Format("2006-01-02 15:04")
+		}
+	}
+	if !until.IsZero() {
+		if isMidnight(until) {
+			to = until.AddDate(0, 0, -1).Format("2006-01-02")
+		} else {
+			to = until.Format("2006-01-02 15:04")
+		}
+	}
+	return from, to
+}
+
+// fmtBound renders a time window bound for JSON; "" when unbounded.
+func fmtBound(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// heatmapDays lists weekdays Monday first, the row order of the heatmap.
+var heatmapDays = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday,
+	time.Friday, time.Saturday, time.Sunday,
+}
+
+var heatShades = []string{"··", "░░", "▒▒", "▓▓", "██"}
+
+// heatShade picks a block by the cell's cost relative to the busiest cell.
+func heatShade(cost, peak float64) string {
+	if cost <= 0 || peak <= 0 {
+		return heatShades[0]
+	}
+	i := 1 + int(cost/peak*4)
+	return heatShades[min(i, len(heatShades)-1)]
+}
+
 type OutputOptions struct {
 	ShowDaily    bool
+	ShowWeekly   bool
+	ShowMonthly  bool
+	Week         usage.WeekOptions
+	Location     *time.Location // zone for displayed times; nil uses local time
 	ShowProjects bool
+	ShowSessions bool
+	ShowBranches bool
+	ShowAgents   bool
+	ShowTools    bool
+	ShowVersions bool
+	ShowTiers    bool
+	ShowSources  bool
+	ShowLimits   bool
+	ShowDiag     bool
+	ShowHeatmap  bool
 	TopN         int
 }
 
-func printJSON(data *ParseResult, opts OutputOptions) {
+type sessionEntry struct {
+	id       string
+	usage    *usage.SessionUsage
+	requests int
+	cost     float64
+}
+
+func sortedSessions(data *usage.ParseResult, topN int) []sessionEntry {
+	var sessions []sessionEntry
+	for id, s := range data.SessionUsage {
+		reqs, cost := s.Totals()
+		sessions = append(sessions, sessionEntry{id, s, reqs, cost})
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].cost != sessions[j].cost {
+			return sessions[i].cost > sessions[j].cost
+		}
+		return sessions[i].id < sessions[j].id
+	})
+	if topN > 0 && len(sessions) > topN {
+		sessions = sessions[:topN]
+	}
+	return sessions
+}
+
+func printJSON(data *usage.ParseResult, opts OutputOptions) {
 	type jsonModelRow struct {
 		Model        string  `json:"model"`
 		InputTokens  int     `json:"input_tokens"`
@@ -102,6 +423,8 @@ func printJSON(data *ParseResult, opts OutputOptions) {
 		CacheWrite   int     `json:"cache_write_tokens"`
 		CacheWrite5m int     `json:"cache_write_5m_tokens"`
 		CacheWrite1h int     `json:"cache_write_1h_tokens"`
+		WebSearches  int     `json:"web_search_requests"`
+		WebFetches   int     `json:"web_fetch_requests"`
 		Requests     int     `json:"requests"`
 		Cost         float64 `json:"cost"`
 	}
@@ -113,22 +436,171 @@ func printJSON(data *ParseResult, opts OutputOptions) {
 		Cost     float64 `json:"cost"`
 	}
 
+	type jsonPeriodModelRow struct {
+		Model        string  `json:"model"`
+		InputTokens  int     `json:"input_tokens"`
+		OutputTokens int     `json:"output_tokens"`
+		Requests     int     `json:"requests"`
+		Cost         float64 `json:"cost"`
+	}
+
+	type jsonPeriodRow struct {
+		Period   string               `json:"period"`
+		Requests int                  `json:"requests"`
+		Cost     float64              `json:"cost"`
+		Models   []jsonPeriodModelRow `json:"models"`
+	}
+
+	periodRows := func(buckets map[string]map[string]*usage.Bucket) []jsonPeriodRow {
+		var rows []jsonPeriodRow
+		for period, models := range buckets {
+			row := jsonPeriodRow{Period: period}
+			for model, b := range models {
+				row.Models = append(row.Models, jsonPeriodModelRow{
+					Model: usage.ModelName(model), InputTokens: b.InputTokens,
+					OutputTokens: b.OutputTokens, Requests: b.Requests, Cost: b.Cost,
+				})
+				row.Requests += b.Requests
+				row.Cost += b.Cost
+			}
+			sort.Slice(row.Models, func(i, j int) bool { return row.Models[i].Cost > row.Models[j].Cost })
+			rows = append(rows, row)
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i].Period > rows[j].Period })
+		return rows
+	}
+
 	type jsonProjectRow struct {
 		Project  string  `json:"project"`
+		Slug     string  `json:"project_slug"`
+		Path     string  `json:"project_path,omitempty"`
+		Model    string  `json:"model"`
+		Requests int     `json:"requests"`
+		Cost     float64 `json:"cost"`
+	}
+
+	type jsonBranchRow struct {
+		Project  string  `json:"project"`
+		Slug     string  `json:"project_slug"`
+		Branch   string  `json:"branch"`
+		Requests int     `json:"requests"`
+		Cost     float64 `json:"cost"`
+	}
+
+	type jsonSessionRow struct {
+		SessionID string   `json:"session_id"`
+		Project   string   `json:"project"`
+		Slug      string   `json:"project_slug"`
+		First     string   `json:"first_timestamp,omitempty"`
+		Last      string   `json:"last_timestamp,omitempty"`
+		Models    []string `json:"models"`
+		Requests  int      `json:"requests"`
+		Cost      float64  `json:"cost"`
+		Subagent  float64  `json:"subagent_cost"`
+	}
+
+	type jsonThreadRow struct {
+		Thread   string  `json:"thread"`
+		Requests int     `json:"requests"`
+		Cost     float64 `json:"cost"`
+	}
+
+	type jsonAgentTypeRow struct {
+		AgentType string  `json:"agent_type"`
+		Model     string  `json:"model"`
+		Requests  int     `json:"requests"`
+		Cost      float64 `json:"cost"`
+	}
+
+	type jsonToolRow struct {
+		Tool     string  `json:"tool"`
+		Server   string  `json:"mcp_server,omitempty"`
+		Calls    int     `json:"calls"`
+		Requests int     `json:"requests"`
+		Cost     float64 `json:"cost"`
+	}
+
+	type jsonServerRow struct {
+		Server   string  `json:"server"`
+		Calls    int     `json:"calls"`
+		Requests int     `json:"requests"`
+		Cost     float64 `json:"cost"`
+	}
+
+	type jsonVersionRow struct {
+		Version       string  `json:"version"`
+		Requests      int     `json:"requests"`
+		InputTokens   int     `json:"input_tokens"`
+		OutputTokens  int     `json:"output_tokens"`
+		CacheRead     int     `json:"cache_read_tokens"`
+		CacheWrite    int     `json:"cache_write_tokens"`
+		CacheHitRatio float64 `json:"cache_hit_ratio"`
+		AvgCost       float64 `json:"avg_cost_per_request"`
+		Cost          float64 `json:"cost"`
+	}
+
+	type jsonTierRow struct {
+		Tier         string  `json:"service_tier"`
+		Requests     int     `json:"requests"`
+		InputTokens  int     `json:"input_tokens"`
+		OutputTokens int     `json:"output_tokens"`
+		Cost         float64 `json:"cost"`
+	}
+
+	type jsonSourceRow struct {
+		Source       string  `json:"source"`
+		BaseDir      string  `json:"base_dir"`
+		Requests     int     `json:"requests"`
+		InputTokens  int     `json:"input_tokens"`
+		OutputTokens int     `json:"output_tokens"`
+		Cost         float64 `json:"cost"`
+	}
+
+	type jsonLimitRow struct {
+		Timestamp string `json:"timestamp,omitempty"`
+		Project   string `json:"project"`
+		SessionID string `json:"session_id"`
+		ResetAt   string `json:"reset_at,omitempty"`
+	}
+
+	type jsonLimitWeekRow struct {
+		Week string `json:"week"`
+		Hits int    `json:"hits"`
+	}
+
+	type jsonIssueRow struct {
+		Kind   string `json:"kind"`
+		Path   string `json:"path"`
+		Line   int    `json:"line,omitempty"`
+		Detail string `json:"detail,omitempty"`
+	}
+
+	type jsonUnmatchedRow struct {
 		Model    string  `json:"model"`
+		PricedAs string  `json:"priced_as"`
 		Requests int     `json:"requests"`
 		Cost     float64 `json:"cost"`
 	}
 
+	type jsonSplitRow struct {
+		Project       string  `json:"project,omitempty"`
+		SessionID     string  `json:"session_id,omitempty"`
+		MainCost      float64 `json:"main_cost"`
+		SubagentCost  float64 `json:"subagent_cost"`
+		SubagentShare float64 `json:"subagent_share"`
+	}
+
 	totals := data.Totals()
 	dateFrom, dateTo := data.DateRange()
+	mainThread, subagents := data.ThreadTotals()
 	var models []jsonModelRow
 	for model, b := range data.ModelUsage {
 		models = append(models, jsonModelRow{
-			Model: shortModel(model), InputTokens: b.InputTokens,
+			Model: usage.ModelName(model), InputTokens: b.InputTokens,
 			OutputTokens: b.OutputTokens, CacheRead: b.CacheRead,
 			CacheWrite: b.TotalCacheWrite(), CacheWrite5m: b.CacheWrite5m,
-			CacheWrite1h: b.CacheWrite1h, Requests: b.Requests, Cost: b.Cost,
+			CacheWrite1h: b.CacheWrite1h, WebSearches: b.WebSearches,
+			WebFetches: b.WebFetches, Requests: b.Requests, Cost: b.Cost,
 		})
 	}
 	sort.Slice(models, func(i, j int) bool { return models[i].Cost > models[j].Cost })
@@ -137,22 +609,44 @@ func printJSON(data *ParseResult, opts OutputOptions) {
 		Summary  interface{} `json:"summary"`
 		Models   interface{} `json:"models"`
 		Daily    interface{} `json:"daily,omitempty"`
+		Weekly   interface{} `json:"weekly,omitempty"`
+		Monthly  interface{} `json:"monthly,omitempty"`
 		Projects interface{} `json:"projects,omitempty"`
+		Sessions interface{} `json:"sessions,omitempty"`
+		Branches interface{} `json:"branches,omitempty"`
+		Agents   interface{} `json:"agents,omitempty"`
+		Tools    interface{} `json:"tools,omitempty"`
+		Servers  interface{} `json:"mcp_servers,omitempty"`
+		Versions interface{} `json:"versions,omitempty"`
+		Tiers    interface{} `json:"service_tiers,omitempty"`
+		Sources  interface{} `json:"sources,omitempty"`
+		Limits   interface{} `json:"limits,omitempty"`
+		Diag     interface{} `json:"diagnostics,omitempty"`
+		Heatmap  interface{} `json:"heatmap,omitempty"`
 	}{
 		Summary: struct {
-			TotalCost         float64 `json:"total_cost"`
-			TotalRequests     int     `json:"total_requests"`
-			TotalInput        int     `json:"total_input_tokens"`
-			TotalOutput       int     `json:"total_output_tokens"`
-			TotalCacheRead    int     `json:"total_cache_read_tokens"`
-			TotalCacheWrite   int     `json:"total_cache_write_tokens"`
-			TotalCacheWrite5m int     `json:"total_cache_write_5m_tokens"`
-			TotalCacheWrite1h int     `json:"total_cache_write_1h_tokens"`
-			DateFrom          string  `json:"date_from,omitempty"`
-			DateTo            string  `json:"date_to,omitempty"`
-			FilesParsed       int     `json:"files_parsed"`
-			DurationMs        int64   `json:"duration_ms"`
-		}{totals.Cost, data.TotalRecords, totals.Input, totals.Output, totals.CacheR, totals.CacheW, totals.CacheW5m, totals.CacheW1h, dateFrom, dateTo, data.TotalFiles, data.Duration.Milliseconds()},
+			TotalCost         float64  `json:"total_cost"`
+			TotalRequests     int      `json:"total_requests"`
+			TotalInput        int      `json:"total_input_tokens"`
+			TotalOutput       int      `json:"total_output_tokens"`
+			TotalCacheRead    int      `json:"total_cache_read_tokens"`
+			TotalCacheWrite   int      `json:"total_cache_write_tokens"`
+			TotalCacheWrite5m int      `json:"total_cache_write_5m_tokens"`
+			TotalCacheWrite1h int      `json:"total_cache_write_1h_tokens"`
+			TotalWebSearches  int      `json:"total_web_search_requests"`
+			TotalWebFetches   int      `json:"total_web_fetch_requests"`
+			DateFrom          string   `json:"date_from,omitempty"`
+			DateTo            string   `json:"date_to,omitempty"`
+			FilesParsed       int      `json:"files_parsed"`
+			DurationMs        int64    `json:"duration_ms"`
+			MainThreadCost    float64  `json:"main_thread_cost"`
+			SubagentCost      float64  `json:"subagent_cost"`
+			Since             string   `json:"since,omitempty"`
+			Until             string   `json:"until,omitempty"`
+			LongContextReqs   int      `json:"long_context_requests"`
+			LongContextCost   float64  `json:"long_context_cost"`
+			Filters           []string `json:"filters,omitempty"`
+		}{totals.Cost, data.TotalRecords, totals.Input, totals.Output, totals.CacheR, totals.CacheW, totals.CacheW5m, totals.CacheW1h, totals.Searches, totals.Fetches, dateFrom, dateTo, data.TotalFiles, data.Duration.Milliseconds(), mainThread.Cost, subagents.Cost, fmtBound(data.Since), fmtBound(data.Until), data.LongContext.Requests, data.LongContext.Cost, data.Filters},
 		Models: models,
 	}
 
@@ -160,7 +654,7 @@ func printJSON(data *ParseResult, opts OutputOptions) {
 		var daily []jsonDailyRow
 		for date, dayModels := range data.DailyUsage {
 			for model, b := range dayModels {
-				daily = append(daily, jsonDailyRow{Date: date, Model: shortModel(model), Requests: b.Requests, Cost: b.Cost})
+				daily = append(daily, jsonDailyRow{Date: date, Model: usage.ModelName(model), Requests: b.Requests, Cost: b.Cost})
 			}
 		}
 		sort.Slice(daily, func(i, j int) bool {
@@ -172,17 +666,201 @@ func printJSON(data *ParseResult, opts OutputOptions) {
 		out.Daily = daily
 	}
 
+	if opts.ShowWeekly {
+		out.Weekly = periodRows(data.PeriodUsage(usage.PeriodWeek, opts.Week))
+	}
+
+	if opts.ShowMonthly {
+		out.Monthly = periodRows(data.PeriodUsage(usage.PeriodMonth, opts.Week))
+	}
+
 	if opts.ShowProjects {
 		var projects []jsonProjectRow
 		for slug, projModels := range data.ProjectUsage {
 			for model, b := range projModels {
-				projects = append(projects, jsonProjectRow{Project: shortProject(slug), Model: shortModel(model), Requests: b.Requests, Cost: b.Cost})
+				projects = append(projects, jsonProjectRow{Project: projectName(data, slug), Slug: slug, Path: data.ProjectPaths[slug], Model: usage.ModelName(model), Requests: b.Requests, Cost: b.Cost})
 			}
 		}
 		sort.Slice(projects, func(i, j int) bool { return projects[i].Cost > projects[j].Cost })
 		out.Projects = projects
 	}
 
+	if opts.ShowSessions {
+		sessions := []jsonSessionRow{}
+		for _, s := range sortedSessions(data, opts.TopN) {
+			row := jsonSessionRow{
+				SessionID: s.id, Project: projectName(data, s.usage.Project), Slug: s.usage.Project,
+				Models: sessionModels(s.usage), Requests: s.requests, Cost: s.cost,
+			}
+			_, row.Subagent, _ = threadSplit(s.usage.Threads)
+			if !s.usage.First.IsZero() {
+				row.First = s.usage.First.Format(time.RFC3339)
+				row.Last = s.usage.Last.Format(time.RFC3339)
+			}
+			sessions = append(sessions, row)
+		}
+		out.Sessions = sessions
+	}
+
+	if opts.ShowAgents {
+		agentTypes := []jsonAgentTypeRow{}
+		for agentType, typeModels := range data.AgentTypeUsage {
+			for model, b := range typeModels {
+				agentTypes = append(agentTypes, jsonAgentTypeRow{AgentType: agentTypeName(agentType), Model: usage.ModelName(model), Requests: b.Requests, Cost: b.Cost})
+			}
+		}
+		sort.Slice(agentTypes, func(i, j int) bool { return agentTypes[i].Cost > agentTypes[j].Cost })
+
+		projects := []jsonSplitRow{}
+		for _, p := range sortedThreadProjects(data, opts.TopN) {
+			mainCost, subCost, share := threadSplit(data.ThreadUsage[p.slug])
+			projects = append(projects, jsonSplitRow{Project: projectName(data, p.slug), MainCost: mainCost, SubagentCost: subCost, SubagentShare: share})
+		}
+
+		sessions := []jsonSplitRow{}
+		for _, s := range sortedSessions(data, opts.TopN) {
+			mainCost, subCost, share := threadSplit(s.usage.Threads)
+			sessions = append(sessions, jsonSplitRow{SessionID: s.id, MainCost: mainCost, SubagentCost: subCost, SubagentShare: share})
+		}
+
+		out.Agents = struct {
+			Threads    []jsonThreadRow    `json:"threads"`
+			AgentTypes []jsonAgentTypeRow `json:"agent_types"`
+			Projects   []jsonSplitRow     `json:"projects"`
+			Sessions   []jsonSplitRow     `json:"sessions"`
+		}{
+			Threads: []jsonThreadRow{
+				{usage.ThreadMain, mainThread.Requests, mainThread.Cost},
+				{usage.ThreadSubagent, subagents.Requests, subagents.Cost},
+			},
+			AgentTypes: agentTypes,
+			Projects:   projects,
+			Sessions:   sessions,
+		}
+	}
+
+	if opts.ShowTools {
+		tools := []jsonToolRow{}
+		for _, e := range sortedToolEntries(data.ToolUsage) {
+			server, _, _ := usage.MCPServer(e.name)
+			tools = append(tools, jsonToolRow{Tool: toolName(e.name), Server: server, Calls: e.usage.Calls, Requests: e.usage.Requests, Cost: e.usage.Cost})
+		}
+		out.Tools = tools
+
+		servers := []jsonServerRow{}
+		for _, e := range sortedToolEntries(data.MCPServerUsage) {
+			servers = append(servers, jsonServerRow{Server: e.name, Calls: e.usage.Calls, Requests: e.usage.Requests, Cost: e.usage.Cost})
+		}
+		out.Servers = servers
+	}
+
+	if opts.ShowVersions {
+		versions := []jsonVersionRow{}
+		for _, v := range sortedVersions(data) {
+			b := v.bucket
+			versions = append(versions, jsonVersionRow{
+				Version: versionName(v.name), Requests: b.Requests,
+				InputTokens: b.InputTokens, OutputTokens: b.OutputTokens,
+				CacheRead: b.CacheRead, CacheWrite: b.TotalCacheWrite(),
+				CacheHitRatio: b.CacheHitRatio(), AvgCost: avgCost(b), Cost: b.Cost,
+			})
+		}
+		out.Versions = versions
+	}
+
+	if opts.ShowDiag {
+		diag := struct {
+			Issues          []jsonIssueRow     `json:"issues"`
+			UnknownDates    int                `json:"unknown_date_records"`
+			UnmatchedModels []jsonUnmatchedRow `json:"unmatched_models"`
+		}{Issues: []jsonIssueRow{}, UnknownDates: data.Diagnostics.UnknownDates, UnmatchedModels: []jsonUnmatchedRow{}}
+		for _, is := range data.Diagnostics.Issues {
+			diag.Issues = append(diag.Issues, jsonIssueRow{Kind: is.Kind, Path: is.Path, Line: is.Line, Detail: is.Detail})
+		}
+		for _, m := range sortedUnmatchedModels(data) {
+			diag.UnmatchedModels = append(diag.UnmatchedModels, jsonUnmatchedRow{
+				Model: m.name, PricedAs: usage.ModelName(usage.DefaultModel), Requests: m.bucket.Requests, Cost: m.bucket.Cost,
+			})
+		}
+		out.Diag = diag
+	}
+
+	if opts.ShowLimits {
+		limits := struct {
+			Hits   []jsonLimitRow     `json:"hits"`
+			Weekly []jsonLimitWeekRow `json:"weekly"`
+		}{Hits: []jsonLimitRow{}, Weekly: []jsonLimitWeekRow{}}
+		for _, h := range data.Limits {
+			limits.Hits = append(limits.Hits, jsonLimitRow{
+				Timestamp: fmtBound(h.Timestamp), Project: projectName(data, h.Project),
+				SessionID: h.Session, ResetAt: fmtBound(h.ResetAt),
+			})
+		}
+		for _, w := range sortedLimitWeeks(data, opts.Week) {
+			limits.Weekly = append(limits.Weekly, jsonLimitWeekRow{Week: w.week, Hits: w.hits})
+		}
+		out.Limits = limits
+	}
+
+	if opts.ShowTiers {
+		tiers := []jsonTierRow{}
+		for _, t := range sortedTiers(data) {
+			b := t.bucket
+			tiers = append(tiers, jsonTierRow{
+				Tier: tierName(t.name), Requests: b.Requests,
+				InputTokens: b.InputTokens, OutputTokens: b.OutputTokens, Cost: b.Cost,
+			})
+		}
+		out.Tiers = tiers
+	}
+
+	if opts.ShowSources {
+		dirs := make(map[string]string)
+		for _, src := range data.Sources {
+			dirs[src.Label] = src.BaseDir
+		}
+		sources := []jsonSourceRow{}
+		for _, src := range sortedSources(data) {
+			b := src.bucket
+			sources = append(sources, jsonSourceRow{
+				Source: src.name, BaseDir: dirs[src.name], Requests: b.Requests,
+				InputTokens: b.InputTokens, OutputTokens: b.OutputTokens, Cost: b.Cost,
+			})
+		}
+		out.Sources = sources
+	}
+
+	if opts.ShowHeatmap {
+		heatmap := struct {
+			Weekdays []string    `json:"weekdays"`
+			Cost     [][]float64 `json:"cost"`
+			Requests [][]int     `json:"requests"`
+		}{}
+		for _, wd := range heatmapDays {
+			costs := make([]float64, 24)
+			reqs := make([]int, 24)
+			for h := range 24 {
+				costs[h] = data.Heatmap[wd][h].Cost
+				reqs[h] = data.Heatmap[wd][h].Requests
+			}
+			heatmap.Weekdays = append(heatmap.Weekdays, wd.String()[:3])
+			heatmap.Cost = append(heatmap.Cost, costs)
+			heatmap.Requests = append(heatmap.Requests, reqs)
+		}
+		out.Heatmap = heatmap
+	}
+
+	if opts.ShowBranches {
+		branches := []jsonBranchRow{}
+		for slug, projBranches := range data.BranchUsage {
+			for branch, b := range projBranches {
+				branches = append(branches, jsonBranchRow{Project: projectName(data, slug), Slug: slug, Branch: branchName(branch), Requests: b.Requests, Cost: b.Cost})
+			}
+		}
+		sort.Slice(branches, func(i, j int) bool { return branches[i].Cost > branches[j].Cost })
+		out.Branches = branches
+	}
+
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
 	if err := enc.Encode(out); err != nil {
@@ -193,10 +871,10 @@ func printJSON(data *ParseResult, opts OutputOptions) {
 
 type modelEntry struct {
 	name   string
-	bucket *Bucket
+	bucket *usage.Bucket
 }
 
-func printSummary(data *ParseResult, opts OutputOptions) {
+func printSummary(data *usage.ParseResult, opts OutputOptions) {
 	bold := color.New(color.Bold)
 	cyan := color.New(color.FgCyan)
 	dim := color.New(color.Faint)
@@ -214,20 +892,54 @@ func printSummary(data *ParseResult, opts OutputOptions) {
 			fmt.Printf("  Period: %s to %s\n", from, to)
 		}
 	}
+	if !data.Since.IsZero() || !data.Until.IsZero() {
+		from, to := fmtRange(data.Since, data.Until)
+		fmt.Printf("  Range: %s to %s\n", from, to)
+	}
+	if len(data.Filters) > 0 {
+		fmt.Printf("  Filters: %s\n", strings.Join(data.Filters, ", "))
+	}
+	if mainThread, subagents := data.ThreadTotals(); subagents.Requests > 0 {
+		_, _, share := threadSplit(map[string]*usage.Bucket{usage.ThreadMain: &mainThread, usage.ThreadSubagent: &subagents})
+		fmt.Printf("  Main thread: %s (%d reqs), subagents: %s (%d reqs, %.1f%%)\n",
+			fmtCost(mainThread.Cost), mainThread.Requests,
+			fmtCost(subagents.Cost), subagents.Requests, share*100)
+	}
+	if lc := data.LongContext; lc.Requests > 0 {
+		fmt.Printf("  Long context (>%dK input): %s (%d reqs)\n",
+			usage.LongContextThreshold/1000, fmtCost(lc.Cost), lc.Requests)
+	}
 	if data.ParseErrors > 0 {
-		dim.Printf("  (%d parse errors skipped)\n", data.ParseErrors)
+		dim.Printf("  (%d parse errors skipped; see -diagnostics)\n", data.ParseErrors)
+	}
+	if n := len(data.Diagnostics.IssuesOf(usage.IssueUnreadable)); n > 0 {
+		dim.Printf("  (%d files or directories could not be read; see -diagnostics)\n", n)
 	}
 	fmt.Println()
 
 	// Model breakdown
+	totals := data.Totals()
+
+	// Server tool columns only appear once a web search or fetch was made,
+	// keeping the usual table within 80 columns.
+	serverTools := totals.Searches+totals.Fetches > 0
+	serverCols := func(search, fetch interface{}) string {
+		if !serverTools {
+			return ""
+		}
+		return fmt.Sprintf(" %7v %7v", search, fetch)
+	}
+	rule := "  " + strings.Repeat("─", 75)
+	if serverTools {
+		rule += strings.Repeat("─", 16)
+	}
+
 	bold.Println("───────────────────────────────────────────────────────────────────────────────")
 	bold.Println("  MODEL BREAKDOWN")
 	bold.Println("───────────────────────────────────────────────────────────────────────────────")
-	fmt.Printf("  %-16s %9s %9s %9s %9s %7s %10s\n",
-		"Model", "Input", "Output", "Cache R", "Cache W", "Reqs", "Cost")
-	fmt.Println("  " + strings.Repeat("─", 75))
-
-	totals := data.Totals()
+	fmt.Printf("  %-16s %9s %9s %9s %9s%s %7s %10s\n",
+		"Model", "Input", "Output", "Cache R", "Cache W", serverCols("Search", "Fetch"), "Reqs", "Cost")
+	fmt.Println(rule)
 
 	var models []modelEntry
 	for name, b := range data.ModelUsage {
@@ -237,88 +949,100 @@ func printSummary(data *ParseResult, opts OutputOptions) {
 
 	for _, m := range models {
 		b := m.bucket
-		fmt.Printf("  %s %9s %9s %9s %9s %7d %s\n",
-			cyan.Sprintf("%-16s", shortModel(m.name)),
+		fmt.Printf("  %s %9s %9s %9s %9s%s %7d %s\n",
+			cyan.Sprintf("%-16s", usage.ModelName(m.name)),
 			fmtTokens(b.InputTokens), fmtTokens(b.OutputTokens),
 			fmtTokens(b.CacheRead), fmtTokens(b.TotalCacheWrite()),
+			serverCols(b.WebSearches, b.WebFetches),
 			b.Requests, colorCost(b.Cost, 10))
 	}
 
-	fmt.Println("  " + strings.Repeat("─", 75))
-	bold.Printf("  %-16s %9s %9s %9s %9s %7d %s\n",
+	fmt.Println(rule)
+	bold.Printf("  %-16s %9s %9s %9s %9s%s %7d %s\n",
 		"TOTAL",
 		fmtTokens(totals.Input), fmtTokens(totals.Output),
 		fmtTokens(totals.CacheR), fmtTokens(totals.CacheW),
+		serverCols(totals.Searches, totals.Fetches),
 		totals.Requests, colorCost(totals.Cost, 10))
 	fmt.Println()
 
-	// Daily breakdown
+	// Period breakdowns
 	if opts.ShowDaily {
+		printPeriodBreakdown("DAILY BREAKDOWN", "Date", data.DailyUsage, opts.TopN)
+	}
+	if opts.ShowWeekly {
+		printPeriodBreakdown("WEEKLY BREAKDOWN", "Week", data.PeriodUsage(usage.PeriodWeek, opts.Week), opts.TopN)
+	}
+	if opts.ShowMonthly {
+		printPeriodBreakdown("MONTHLY BREAKDOWN", "Month", data.PeriodUsage(usage.PeriodMonth, opts.Week), opts.TopN)
+	}
+
+	// Project breakdown
+	if opts.ShowProjects {
 		bold.Println("───────────────────────────────────────────────────────────────────────────────")
-		bold.Println("  DAILY BREAKDOWN")
+		bold.Println("  PROJECT BREAKDOWN")
 		bold.Println("───────────────────────────────────────────────────────────────────────────────")
-		fmt.Printf("  %-12s %-16s %9s %9s %7s %10s\n",
-			"Date", "Model", "Input", "Output", "Reqs", "Cost")
+		fmt.Printf("  %-35s %-16s %7s %10s\n",
+			"Project", "Model", "Reqs", "Cost")
 		fmt.Println("  " + strings.Repeat("─", 75))
 
-		var dates []string
-		for d := range data.DailyUsage {
-			dates = append(dates, d)
+		var projects []projTotal
+		for slug, projModels := range data.ProjectUsage {
+			var t float64
+			for _, b := range projModels {
+				t += b.Cost
+			}
+			projects = append(projects, projTotal{slug, t})
 		}
-		sort.Sort(sort.Reverse(sort.StringSlice(dates)))
-		if opts.TopN > 0 && len(dates) > opts.TopN {
-			dates = dates[:opts.TopN]
+		sort.Slice(projects, func(i, j int) bool { return projects[i].total > projects[j].total })
+		if opts.TopN > 0 && len(projects) > opts.TopN {
+			projects = projects[:opts.TopN]
 		}
 
-		for _, date := range dates {
-			dayModels := data.DailyUsage[date]
-			var dayCost float64
-			var dayReqs int
+		for _, proj := range projects {
+			projModels := data.ProjectUsage[proj.slug]
+			name := projectName(data, proj.slug)
 
 			var sorted []modelEntry
-			for name, b := range dayModels {
-				sorted = append(sorted, modelEntry{name, b})
-				dayCost += b.Cost
-				dayReqs += b.Requests
+			for mname, b := range projModels {
+				sorted = append(sorted, modelEntry{mname, b})
 			}
 			sort.Slice(sorted, func(i, j int) bool { return sorted[i].bucket.Cost > sorted[j].bucket.Cost })
 
 			first := true
 			for _, m := range sorted {
 				b := m.bucket
-				d := ""
+				n := ""
 				if first {
-					d = date
+					n = name
+					if len(n) > 35 {
+						n = n[:32] + "..."
+					}
 				}
-				fmt.Printf("  %-12s %s %9s %9s %7d %s\n",
-					d, cyan.Sprintf("%-16s", shortModel(m.name)),
-					fmtTokens(b.InputTokens), fmtTokens(b.OutputTokens),
+				fmt.Printf("  %-35s %s %7d %s\n",
+					n, cyan.Sprintf("%-16s", usage.ModelName(m.name)),
 					b.Requests, colorCost(b.Cost, 10))
 				first = false
 			}
-			fmt.Printf("  %-12s %-16s %9s %9s %7d %s\n",
-				"", "", "", "", dayReqs, colorCost(dayCost, 10))
+			fmt.Printf("  %-35s %-16s %7s %s\n",
+				"", "SUBTOTAL", "", colorCost(proj.total, 10))
 			fmt.Println()
 		}
 	}
 
-	// Project breakdown
-	if opts.ShowProjects {
+	// Branch breakdown
+	if opts.ShowBranches {
 		bold.Println("───────────────────────────────────────────────────────────────────────────────")
-		bold.Println("  PROJECT BREAKDOWN")
+		bold.Println("  BRANCH BREAKDOWN")
 		bold.Println("───────────────────────────────────────────────────────────────────────────────")
-		fmt.Printf("  %-35s %-16s %7s %10s\n",
-			"Project", "Model", "Reqs", "Cost")
+		fmt.Printf("  %-35s %-22s %7s %10s\n",
+			"Project", "Branch", "Reqs", "Cost")
 		fmt.Println("  " + strings.Repeat("─", 75))
 
-		type projTotal struct {
-			slug  string
-			total float64
-		}
 		var projects []projTotal
-		for slug, projModels := range data.ProjectUsage {
+		for slug, projBranches := range data.BranchUsage {
 			var t float64
-			for _, b := range projModels {
+			for _, b := range projBranches {
 				t += b.Cost
 			}
 			projects = append(projects, projTotal{slug, t})
@@ -329,33 +1053,457 @@ func printSummary(data *ParseResult, opts OutputOptions) {
 		}
 
 		for _, proj := range projects {
-			projModels := data.ProjectUsage[proj.slug]
-			name := shortProject(proj.slug)
-
 			var sorted []modelEntry
-			for mname, b := range projModels {
-				sorted = append(sorted, modelEntry{mname, b})
+			for branch, b := range data.BranchUsage[proj.slug] {
+				sorted = append(sorted, modelEntry{branch, b})
 			}
 			sort.Slice(sorted, func(i, j int) bool { return sorted[i].bucket.Cost > sorted[j].bucket.Cost })
 
 			first := true
-			for _, m := range sorted {
-				b := m.bucket
+			for _, e := range sorted {
 				n := ""
 				if first {
-					n = name
+					n = projectName(data, proj.slug)
 					if len(n) > 35 {
 						n = n[:32] + "..."
 					}
 				}
+				branch := branchName(e.name)
+				if len(branch) > 22 {
+					branch = branch[:19] + "..."
+				}
 				fmt.Printf("  %-35s %s %7d %s\n",
-					n, cyan.Sprintf("%-16s", shortModel(m.name)),
-					b.Requests, colorCost(b.Cost, 10))
+					n, cyan.Sprintf("%-22s", branch),
+					e.bucket.Requests, colorCost(e.bucket.Cost, 10))
 				first = false
 			}
-			fmt.Printf("  %-35s %-16s %7s %s\n",
-				"", "SUBTOTAL", "", colorCost(proj.total, 10))
+			if len(sorted) > 1 {
+				fmt.Printf("  %-35s %-22s %7s %s\n",
+					"", "SUBTOTAL", "", colorCost(proj.total, 10))
+			}
+			fmt.Println()
+		}
+	}
+
+	// Session breakdown
+	if opts.ShowSessions {
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		bold.Println("  SESSION BREAKDOWN")
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		fmt.Printf("  %-8s %-18s %-16s %-13s %5s %10s\n",
+			"Session", "Project", "Started", "Models", "Reqs", "Cost")
+		fmt.Println("  " + strings.Repeat("─", 75))
+
+		for _, s := range sortedSessions(data, opts.TopN) {
+			project := projectName(data, s.usage.Project)
+			if len(project) > 18 {
+				project = project[:15] + "..."
+			}
+			started := "unknown"
+			if !s.usage.First.IsZero() {
+				started = s.usage.First.In(location(opts.Location)).Format("2006-01-02 15:04")
+			}
+			models := sessionModels(s.usage)
+			modelStr := models[0]
+			if len(models) > 1 {
+				modelStr = fmt.Sprintf("%s +%d", models[0], len(models)-1)
+			}
+			fmt.Printf("  %-8s %-18s %-16s %s %5d %s\n",
+				shortSession(s.id), project, started,
+				cyan.Sprintf("%-13s", modelStr), s.requests, colorCost(s.cost, 10))
+		}
+		fmt.Println()
+	}
+	// Subagent breakdown
+	if opts.ShowAgents {
+		mainThread, subagents := data.ThreadTotals()
+		_, _, share := threadSplit(map[string]*usage.Bucket{usage.ThreadMain: &mainThread, usage.ThreadSubagent: &subagents})
+
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		bold.Println("  SUBAGENT BREAKDOWN")
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		fmt.Printf("  %-35s %7s %10s %7s\n", "Thread", "Reqs", "Cost", "Share")
+		fmt.Println("  " + strings.Repeat("─", 75))
+		fmt.Printf("  %s %7d %s %6.1f%%\n",
+			cyan.Sprintf("%-35s", "Main thread"), mainThread.Requests, colorCost(mainThread.Cost, 10), (1-share)*100)
+		fmt.Printf("  %s %7d %s %6.1f%%\n",
+			cyan.Sprintf("%-35s", "Subagents"), subagents.Requests, colorCost(subagents.Cost, 10), share*100)
+		fmt.Println()
+
+		if len(data.AgentTypeUsage) > 0 {
+			fmt.Printf("  %-35s %-16s %7s %10s\n", "Agent Type", "Model", "Reqs", "Cost")
+			fmt.Println("  " + strings.Repeat("─", 75))
+
+			var agentTypes []projTotal
+			for agentType, typeModels := range data.AgentTypeUsage {
+				var t float64
+				for _, b := range typeModels {
+					t += b.Cost
+				}
+				agentTypes = append(agentTypes, projTotal{agentType, t})
+			}
+			sort.Slice(agentTypes, func(i, j int) bool { return agentTypes[i].total > agentTypes[j].total })
+
+			for _, at := range agentTypes {
+				var sorted []modelEntry
+				for name, b := range data.AgentTypeUsage[at.slug] {
+					sorted = append(sorted, modelEntry{name, b})
+				}
+				sort.Slice(sorted, func(i, j int) bool { return sorted[i].bucket.Cost > sorted[j].bucket.Cost })
+
+				first := true
+				for _, m := range sorted {
+					n := ""
+					if first {
+						n = agentTypeName(at.slug)
+					}
+					fmt.Printf("  %-35s %s %7d %s\n",
+						n, cyan.Sprintf("%-16s", usage.ModelName(m.name)),
+						m.bucket.Requests, colorCost(m.bucket.Cost, 10))
+					first = false
+				}
+			}
+			fmt.Println()
+		}
+
+		fmt.Printf("  %-35s %10s %11s %7s\n", "Project", "Main", "Subagents", "Share")
+		fmt.Println("  " + strings.Repeat("─", 75))
+		for _, p := range sortedThreadProjects(data, opts.TopN) {
+			mainCost, subCost, share := threadSplit(data.ThreadUsage[p.slug])
+			name := projectName(data, p.slug)
+			if len(name) > 35 {
+				name = name[:32] + "..."
+			}
+			fmt.Printf("  %-35s %s %s %6.1f%%\n",
+				name, colorCost(mainCost, 10), colorCost(subCost, 11), share*100)
+		}
+		fmt.Println()
+
+		fmt.Printf("  %-8s %-26s %10s %11s %7s\n", "Session", "Project", "Main", "Subagents", "Share")
+		fmt.Println("  " + strings.Repeat("─", 75))
+		for _, s := range sortedSessions(data, opts.TopN) {
+			mainCost, subCost, share := threadSplit(s.usage.Threads)
+			project := projectName(data, s.usage.Project)
+			if len(project) > 26 {
+				project = project[:23] + "..."
+			}
+			fmt.Printf("  %-8s %-26s %s %s %6.1f%%\n",
+				shortSession(s.id), project, colorCost(mainCost, 10), colorCost(subCost, 11), share*100)
+		}
+		fmt.Println()
+	}
+	// Tool breakdown
+	if opts.ShowTools {
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		bold.Println("  TOOL BREAKDOWN")
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		fmt.Printf("  %-35s %7s %7s %10s\n", "Tool", "Calls", "Reqs", "Cost")
+		fmt.Println("  " + strings.Repeat("─", 75))
+
+		// MCP tools are listed under their server, ranked by the server total.
+		type toolRow struct {
+			entry    toolEntry
+			children []toolEntry
+		}
+		var rows []toolRow
+		mcpTools := make(map[string][]toolEntry)
+		for _, e := range sortedToolEntries(data.ToolUsage) {
+			if server, _, ok := usage.MCPServer(e.name); ok {
+				mcpTools[server] = append(mcpTools[server], e)
+				continue
+			}
+			rows = append(rows, toolRow{entry: e})
+		}
+		for _, e := range sortedToolEntries(data.MCPServerUsage) {
+			rows = append(rows, toolRow{entry: toolEntry{"mcp:" + e.name, e.usage}, children: mcpTools[e.name]})
+		}
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].entry.usage.Cost > rows[j].entry.usage.Cost })
+		if opts.TopN > 0 && len(rows) > opts.TopN {
+			rows = rows[:opts.TopN]
+		}
+
+		for _, row := range rows {
+			u := row.entry.usage
+			calls := fmt.Sprintf("%d", u.Calls)
+			if row.entry.name == "" {
+				calls = "-"
+			}
+			fmt.Printf("  %s %7s %7d %s\n",
+				cyan.Sprintf("%-35s", toolName(row.entry.name)), calls, u.Requests, colorCost(u.Cost, 10))
+			for _, c := range row.children {
+				_, tool, _ := usage.MCPServer(c.name)
+				if len(tool) > 33 {
+					tool = tool[:30] + "..."
+				}
+				fmt.Printf("    %-33s %7d %7d %s\n",
+					tool, c.usage.Calls, c.usage.Requests, colorCost(c.usage.Cost, 10))
+			}
+		}
+		fmt.Println()
+	}
+	// Version breakdown
+	if opts.ShowVersions {
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		bold.Println("  VERSION BREAKDOWN")
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		fmt.Printf("  %-16s %9s %9s %9s %9s %7s %10s\n",
+			"Version", "Input", "Output", "Cache %", "Avg/Req", "Reqs", "Cost")
+		fmt.Println("  " + strings.Repeat("─", 75))
+
+		versions := sortedVersions(data)
+		if opts.TopN > 0 && len(versions) > opts.TopN {
+			versions = versions[:opts.TopN]
+		}
+		for _, v := range versions {
+			b := v.bucket
+			fmt.Printf("  %s %9s %9s %8.1f%% %9s %7d %s\n",
+				cyan.Sprintf("%-16s", versionName(v.name)),
+				fmtTokens(b.InputTokens), fmtTokens(b.OutputTokens),
+				b.CacheHitRatio()*100, fmtCost(avgCost(b)),
+				b.Requests, colorCost(b.Cost, 10))
+		}
+		fmt.Println()
+	}
+	// Heatmap
+	if opts.ShowHeatmap {
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		bold.Println("  HOURLY HEATMAP")
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+
+		var peak float64
+		for _, wd := range heatmapDays {
+			for h := range 24 {
+				peak = max(peak, data.Heatmap[wd][h].Cost)
+			}
+		}
+
+		header := "  " + strings.Repeat(" ", 5)
+		for h := 0; h < 24; h += 3 {
+			header += fmt.Sprintf("%-6s", fmt.Sprintf("%02d", h))
+		}
+		fmt.Printf("%s %10s\n", header, "Cost")
+		fmt.Println("  " + strings.Repeat("─", 75))
+
+		for _, wd := range heatmapDays {
+			var cells strings.Builder
+			var dayCost float64
+			for h := range 24 {
+				c := data.Heatmap[wd][h].Cost
+				cells.WriteString(colorize(heatShade(c, peak), c))
+				dayCost += c
+			}
+			fmt.Printf("  %-5s%s %s\n", wd.String()[:3], cells.String(), colorCost(dayCost, 10))
+		}
+		dim.Printf("  %s < 25%%  %s < 50%%  %s < 75%%  %s ≥ 75%% of the busiest hour (%s)\n",
+			heatShades[1], heatShades[2], heatShades[3], heatShades[4], fmtCost(peak))
+		fmt.Println()
+	}
+
+	// Source breakdown
+	if opts.ShowSources {
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		bold.Println("  SOURCE BREAKDOWN")
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		fmt.Printf("  %-16s %9s %9s %9s %7s %10s\n",
+			"Source", "Input", "Output", "Share", "Reqs", "Cost")
+		fmt.Println("  " + strings.Repeat("─", 75))
+
+		for _, src := range sortedSources(data) {
+			b := src.bucket
+			var share float64
+			if totals.Cost > 0 {
+				share = b.Cost / totals.Cost
+			}
+			name := src.name
+			if len(name) > 16 {
+				name = name[:13] + "..."
+			}
+			fmt.Printf("  %s %9s %9s %8.1f%% %7d %s\n",
+				cyan.Sprintf("%-16s", name),
+				fmtTokens(b.InputTokens), fmtTokens(b.OutputTokens),
+				share*100, b.Requests, colorCost(b.Cost, 10))
+		}
+		fmt.Println()
+	}
+
+	// Service tier breakdown
+	if opts.ShowTiers {
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		bold.Println("  SERVICE TIER BREAKDOWN")
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		fmt.Printf("  %-16s %9s %9s %9s %7s %10s\n",
+			"Tier", "Input", "Output", "Share", "Reqs", "Cost")
+		fmt.Println("  " + strings.Repeat("─", 75))
+
+		for _, t := range sortedTiers(data) {
+			b := t.bucket
+			var share float64
+			if totals.Cost > 0 {
+				share = b.Cost / totals.Cost
+			}
+			fmt.Printf("  %s %9s %9s %8.1f%% %7d %s\n",
+				cyan.Sprintf("%-16s", tierName(t.name)),
+				fmtTokens(b.InputTokens), fmtTokens(b.OutputTokens),
+				share*100, b.Requests, colorCost(b.Cost, 10))
+		}
+		fmt.Println()
+	}
+
+	// Usage limits
+	if opts.ShowLimits {
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		bold.Println("  USAGE LIMITS")
+		bold.Println("───────────────────────────────────────────────────────────────────────────────")
+		if len(data.Limits) == 0 {
+			dim.Println("  No usage limit hits.")
+			fmt.Println()
+		} else {
+			loc := location(opts.Location)
+			fmt.Printf("  %-17s %-35s %-9s %s\n",
+				"Hit at", "Project", "Session", "Resets at")
+			fmt.Println("  " + strings.Repeat("─", 75))
+
+			hits := data.Limits
+			if opts.TopN > 0 && len(hits) > opts.TopN {
+				hits = hits[len(hits)-opts.TopN:]
+			}
+			for i := len(hits) - 1; i >= 0; i-- {
+				h := hits[i]
+				project := projectName(data, h.Project)
+				if len(project) > 35 {
+					project = project[:32] + "..."
+				}
+				hitAt, resets := "unknown", "unknown"
+				if !h.Timestamp.IsZero() {
+					hitAt = h.Timestamp.In(loc).Format("2006-01-02 15:04")
+				}
+				if !h.ResetAt.IsZero() {
+					resets = h.ResetAt.In(loc).Format("2006-01-02 15:04")
+				}
+				fmt.Printf("  %-17s %-35s %-9s %s\n", hitAt, project, shortSession(h.Session), resets)
+			}
 			fmt.Println()
+
+			fmt.Printf("  %-12s %7s\n", "Week", "Hits")
+			fmt.Println("  " + strings.Repeat("─", 20))
+			for _, w := range sortedLimitWeeks(data, opts.Week) {
+				fmt.Printf("  %-12s %7d\n", w.week, w.hits)
+			}
+			fmt.Println()
+		}
+	}
+
+	if opts.ShowDiag {
+		printDiagnostics(data)
+	}
+}
+
+// printDiagnostics lists every issue found while parsing, grouped by kind.
+func printDiagnostics(data *usage.ParseResult) {
+	bold := color.New(color.Bold)
+	cyan := color.New(color.FgCyan)
+	dim := color.New(color.Faint)
+	d := &data.Diagnostics
+
+	bold.Println("───────────────────────────────────────────────────────────────────────────────")
+	bold.Println("  DIAGNOSTICS")
+	bold.Println("───────────────────────────────────────────────────────────────────────────────")
+	if d.Count() == 0 && d.UnknownDates == 0 {
+		dim.Println("  No problems found.")
+		fmt.Println()
+		return
+	}
+
+	for _, kind := range []struct{ kind, title string }{
+		{usage.IssueUnreadable, "Unreadable files and directories"},
+		{usage.IssueMalformed, "Malformed lines"},
+		{usage.IssueBadTimestamp, "Unparseable timestamps"},
+		{usage.IssueMissingTimestamp, "Records without a timestamp"},
+	} {
+		issues := d.IssuesOf(kind.kind)
+		if len(issues) == 0 {
+			continue
+		}
+		bold.Printf("  %s (%d)\n", kind.title, len(issues))
+		for _, is := range issues {
+			loc := is.Path
+			if is.Line > 0 {
+				loc = fmt.Sprintf("%s:%d", is.Path, is.Line)
+			}
+			if is.Detail != "" {
+				fmt.Printf("    %s  %s\n", loc, dim.Sprint(is.Detail))
+			} else {
+				fmt.Printf("    %s\n", loc)
+			}
+		}
+		fmt.Println()
+	}
+
+	if d.UnknownDates > 0 {
+		bold.Printf("  Records with unknown dates: %d\n", d.UnknownDates)
+		dim.Println("    Listed as \"unknown\" in the daily breakdown; excluded whenever a date range is set.")
+		fmt.Println()
+	}
+
+	if len(d.UnmatchedModels) > 0 {
+		bold.Printf("  Unmatched models, priced as %s (%d)\n", usage.ModelName(usage.DefaultModel), len(d.UnmatchedModels))
+		for _, m := range sortedUnmatchedModels(data) {
+			fmt.Printf("    %s %7d reqs %s\n",
+				cyan.Sprintf("%-34s", m.name), m.bucket.Requests, colorCost(m.bucket.Cost, 10))
+		}
+		fmt.Println()
+	}
+}
+
+// printPeriodBreakdown lists usage per period, newest first, with a row per
+// model and a subtotal per period.
+func printPeriodBreakdown(title, column string, buckets map[string]map[string]*usage.Bucket, topN int) {
+	bold := color.New(color.Bold)
+	cyan := color.New(color.FgCyan)
+
+	bold.Println("───────────────────────────────────────────────────────────────────────────────")
+	bold.Println("  " + title)
+	bold.Println("───────────────────────────────────────────────────────────────────────────────")
+	fmt.Printf("  %-12s %-16s %9s %9s %7s %10s\n",
+		column, "Model", "Input", "Output", "Reqs", "Cost")
+	fmt.Println("  " + strings.Repeat("─", 75))
+
+	var periods []string
+	for p := range buckets {
+		periods = append(periods, p)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(periods)))
+	if topN > 0 && len(periods) > topN {
+		periods = periods[:topN]
+	}
+
+	for _, period := range periods {
+		var periodCost float64
+		var periodReqs int
+
+		var sorted []modelEntry
+		for name, b := range buckets[period] {
+			sorted = append(sorted, modelEntry{name, b})
+			periodCost += b.Cost
+			periodReqs += b.Requests
+		}
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].bucket.Cost > sorted[j].bucket.Cost })
+
+		first := true
+		for _, m := range sorted {
+			b := m.bucket
+			label := ""
+			if first {
+				label = period
+			}
+			fmt.Printf("  %-12s %s %9s %9s %7d %s\n",
+				label, cyan.Sprintf("%-16s", usage.ModelName(m.name)),
+				fmtTokens(b.InputTokens), fmtTokens(b.OutputTokens),
+				b.Requests, colorCost(b.Cost, 10))
+			first = false
 		}
+		fmt.Printf("  %-12s %-16s %9s %9s %7d %s\n",
+			"", "", "", "", periodReqs, colorCost(periodCost, 10))
+		fmt.Println()
 	}
 }
@@ -3,6 +3,8 @@ package main
 import (
 	"testing"
 	"time"
+
+	"github.com/backstabslash/goccc/usage"
 )
 
 func TestFmtTokens(t *testing.T) {
@@ -69,6 +71,33 @@ func TestShortProject(t *testing.T) {
 	}
 }
 
+func TestShortPath(t *testing.T) {
+	tests := map[string]string{
+		`C:\Users\alice\git\webapp`: "git/webapp",
+		"/home/bob/src/my-app":      "src/my-app",
+		"/Users/carol/work/app/":    "work/app",
+		"/home/bob":                 "~",
+		"/srv/deploy/app":           "/srv/deploy/app",
+		`D:\work\app`:               "D:/work/app",
+		"/home/bob/a/very/long/path/that/exceeds/the/forty/character/limit": "...hat/exceeds/the/forty/character/limit",
+	}
+	for in, want := range tests {
+		if got := shortPath(in); got != want {
+			t.Errorf("shortPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestProjectName(t *testing.T) {
+	data := &usage.ParseResult{ProjectPaths: map[string]string{"C--Users-alice-git-webapp": `C:\Users\alice\git\webapp`}}
+	if got := projectName(data, "C--Users-alice-git-webapp"); got != "git/webapp" {
+		t.Errorf("projectName = %q, want git/webapp", got)
+	}
+	if got := projectName(data, "-home-bob-proj"); got != shortProject("-home-bob-proj") {
+		t.Errorf("projectName without a recorded path = %q, want the slug guess", got)
+	}
+}
+
 func TestFmtDuration(t *testing.T) {
 	tests := []struct {
 		input    time.Duration
@@ -88,9 +117,55 @@ func TestFmtDuration(t *testing.T) {
 	}
 }
 
-func TestTotalCacheWrite(t *testing.T) {
-	b := &Bucket{CacheWrite5m: 100, CacheWrite1h: 200}
-	if got := b.TotalCacheWrite(); got != 300 {
-		t.Errorf("TotalCacheWrite() = %d, want 300", got)
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.1.47", "2.1.47", 0},
+		{"2.1.10", "2.1.9", 1},
+		{"2.0.76", "2.1.0", -1},
+		{"2.1", "2.1.1", -1},
+		{"2.1.0-beta", "2.1.0-alpha", 1},
+		{"", "1.0.0", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestHeatShade(t *testing.T) {
+	tests := []struct {
+		cost, peak float64
+		want       string
+	}{
+		{0, 10, "··"},
+		{1, 10, "░░"},
+		{3, 10, "▒▒"},
+		{6, 10, "▓▓"},
+		{8, 10, "██"},
+		{10, 10, "██"},
+		{0, 0, "··"},
+	}
+	for _, tt := range tests {
+		if got := heatShade(tt.cost, tt.peak); got != tt.want {
+			t.Errorf("heatShade(%v, %v) = %q, want %q", tt.cost, tt.peak, got, tt.want)
+		}
+	}
+}
+
+func TestFmtRange(t *testing.T) {
+	since := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	if from, to := fmtRange(since, until); from != "2026-09-01" || to != "2026-09-30" {
+		t.Errorf("fmtRange = %q, %q; want 2026-09-01, 2026-09-30", from, to)
+	}
+	if from, to := fmtRange(since.Add(90*time.Minute), time.Time{}); from != "2026-09-01 01:30" || to != "now" {
+		t.Errorf("fmtRange = %q, %q; want 2026-09-01 01:30, now", from, to)
+	}
+	if from, _ := fmtRange(time.Time{}, until); from != "beginning" {
+		t.Errorf("fmtRange from = %q, want beginning", from)
 	}
 }
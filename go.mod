@@ -2,7 +2,10 @@ module github.com/backstabslash/goccc
 
 go 1.26.0
 
-require github.com/fatih/color v1.18.0
+require (
+	github.com/fatih/color v1.18.0
+	github.com/klauspost/compress v1.18.0
+)
 
 require (
 	github.com/mattn/go-colorable v0.1.14 // indirect
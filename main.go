@@ -4,10 +4,16 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"runtime/debug"
+	"strings"
 	"time"
+	_ "time/tzdata" // -tz must work on systems without a zoneinfo database
 
+	"github.com/backstabslash/goccc/usage"
 	"github.com/fatih/color"
 )
 
@@ -39,44 +45,101 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "archive" {
+		os.Exit(runArchive(os.Args[2:]))
+	}
+
 	days := flag.Int("days", 0, "Only show usage from the last N days (0 = all time)")
+	since := flag.String("since", "", "Only show usage from this date or time on (YYYY-MM-DD, RFC 3339, today, yesterday, or relative: 12h, 3d, 2w, 1m)")
+	until := flag.String("until", "", "Only show usage up to and including this date, or before this time (same forms as -since)")
+	tz := flag.String("tz", "", "Time zone for day boundaries and hours: IANA name (e.g. Europe/Berlin) or UTC (default local)")
+	dayStart := flag.String("day-start", "00:00", "Time of day (HH:MM) at which a new day begins for daily totals and the heatmap")
 	project := flag.String("project", "", "Filter by project name (substring match)")
+	projectRegex := flag.String("project-regex", "", "Filter by project path (regular expression)")
+	excludeProject := flag.String("exclude-project", "", "Leave out projects matching this name (substring match)")
+	excludeProjectRegex := flag.String("exclude-project-regex", "", "Leave out projects whose path matches this regular expression")
+	model := flag.String("model", "", "Filter by model id or name (substring match, e.g. opus or 'Sonnet 4.6')")
+	modelRegex := flag.String("model-regex", "", "Filter by model id or name (regular expression)")
 	daily := flag.Bool("daily", false, "Show daily breakdown")
+	weekly := flag.Bool("weekly", false, "Show weekly breakdown")
+	monthly := flag.Bool("monthly", false, "Show monthly breakdown")
+	period := flag.String("period", "", "Show breakdown by period: day, week or month")
+	weekStart := flag.String("week-start", "mon", "First day of the week for weekly breakdowns")
+	isoWeek := flag.Bool("iso-week", false, "Label weeks by ISO 8601 week number (weeks start on Monday)")
 	projects := flag.Bool("projects", false, "Show per-project breakdown")
-	all := flag.Bool("all", false, "Show all breakdowns (daily + projects)")
+	sessions := flag.Bool("sessions", false, "Show per-session breakdown")
+	tools := flag.Bool("tools", false, "Show tool-use breakdown (calls and attributed cost per tool)")
+	versions := flag.Bool("versions", false, "Show breakdown by Claude Code version")
+	tiers := flag.Bool("tiers", false, "Show breakdown by service tier (standard, priority, batch)")
+	limits := flag.Bool("limits", false, "Show when subscription usage limits were hit, with per-week counts")
+	heatmap := flag.Bool("heatmap", false, "Show spend by weekday and hour of day")
+	agents := flag.Bool("agents", false, "Show main-thread vs subagent cost split")
+	branches := flag.Bool("branches", false, "Show per-branch breakdown, grouped by project")
+	branch := flag.String("branch", "", "Filter by git branch (exact name or glob, e.g. 'feature/*')")
+	all := flag.Bool("all", false, "Show every breakdown: daily, weekly, monthly, projects, sessions, branches, agents, tools, versions, tiers, limits and heatmap")
 	topN := flag.Int("top", 0, "Max entries in breakdowns (0 = all)")
+	jobs := flag.Int("jobs", runtime.NumCPU(), "Number of log files to parse concurrently")
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: cannot determine home directory: %v\n", err)
 		os.Exit(1)
 	}
-	baseDir := flag.String("base-dir", filepath.Join(homeDir, ".claude"), "Base directory for Claude Code data")
+	var baseDirs sourcesFlag
+	flag.Var(&baseDirs, "base-dir", "Base directory for Claude Code data, as path or label=path; repeat to combine several (default ~/.claude)")
+	var files filesFlag
+	flag.Var(&files, "f", "Read this JSONL file instead of the projects directory; repeatable, globs allowed, - for stdin")
+	includeArchive := flag.Bool("include-archive", false, "Also read logs saved by goccc archive (live copies win over archived ones)")
+	archiveDir := flag.String("archive-dir", "", "Archive directory for -include-archive (default <base-dir>/goccc-archive)")
 	jsonOutput := flag.Bool("json", false, "Output as JSON")
+	records := flag.String("records", "", "Export one row per deduplicated request instead of the report: ndjson or csv")
 	noColor := flag.Bool("no-color", false, "Disable colored output")
 	showVersion := flag.Bool("version", false, "Show version")
+	diagnostics := flag.Bool("diagnostics", false, "List malformed lines, bad timestamps and unmatched models (line problems are reported for every file read, whatever the date range)")
+	strict := flag.Bool("strict", false, "Exit with status 3 if any diagnostics were found")
+	noCache := flag.Bool("no-cache", false, "Disable the persistent parse cache")
+	watch := flag.Bool("watch", false, "Keep running and show live totals for today, the current session and the last hour")
+	interval := flag.Duration("interval", 2*time.Second, "How often -watch checks the logs for new requests")
 	statusline := flag.Bool("statusline", false, "Statusline mode: read session JSON from stdin, output formatted cost line")
 
 	flag.IntVar(days, "d", 0, "Short for -days")
 	flag.StringVar(project, "p", "", "Short for -project")
 	flag.IntVar(topN, "n", 0, "Short for -top")
+	flag.IntVar(jobs, "j", runtime.NumCPU(), "Short for -jobs")
 	flag.BoolVar(showVersion, "V", false, "Short for -version")
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: goccc [flags]\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: goccc [flags] [-f file ...] [-]\n")
+		fmt.Fprintf(os.Stderr, "       goccc archive [flags]\n\n")
 		fmt.Fprintf(os.Stderr, "A CLI cost calculator for Claude Code.\n")
 		fmt.Fprintf(os.Stderr, "Parses JSONL logs from ~/.claude/projects/ and breaks down\n")
-		fmt.Fprintf(os.Stderr, "spending by model, day, and project.\n\n")
+		fmt.Fprintf(os.Stderr, "spending by model, day, project, and session.\n\n")
 		fmt.Fprintf(os.Stderr, "Examples:\n")
 		fmt.Fprintf(os.Stderr, "  goccc                          All-time summary\n")
 		fmt.Fprintf(os.Stderr, "  goccc -days 7 -all             Last 7 days, all breakdowns\n")
 		fmt.Fprintf(os.Stderr, "  goccc -days 1                  Today's usage\n")
+		fmt.Fprintf(os.Stderr, "  goccc -since 2w -daily         Last two weeks, day by day\n")
 		fmt.Fprintf(os.Stderr, "  goccc -project webapp -daily   Filter by project with daily breakdown\n")
-		fmt.Fprintf(os.Stderr, "  goccc -json | jq '.summary'    JSON output for scripting\n\n")
+		fmt.Fprintf(os.Stderr, "  goccc -monthly -top 6          Last six months, per model\n")
+		fmt.Fprintf(os.Stderr, "  goccc -sessions -top 10        Ten most expensive conversations\n")
+		fmt.Fprintf(os.Stderr, "  goccc -branch 'feat/*' -daily  Daily cost of feature branches\n")
+		fmt.Fprintf(os.Stderr, "  goccc -model opus -projects    Opus spend per project\n")
+		fmt.Fprintf(os.Stderr, "  goccc -base-dir ~/.claude -base-dir work=$HOME/.claude-work\n")
+		fmt.Fprintf(os.Stderr, "                                 Combine config dirs, with a per-source breakdown\n")
+		fmt.Fprintf(os.Stderr, "  goccc -f transcript.jsonl      Cost of a single transcript\n")
+		fmt.Fprintf(os.Stderr, "  goccc - < transcript.jsonl     Read a transcript from stdin\n")
+		fmt.Fprintf(os.Stderr, "  goccc -watch                   Live totals, updated as requests arrive\n")
+		fmt.Fprintf(os.Stderr, "  goccc -json | jq '.summary'    JSON output for scripting\n")
+		fmt.Fprintf(os.Stderr, "  goccc -records csv > reqs.csv  Every request as a CSV row\n")
+		fmt.Fprintf(os.Stderr, "  goccc archive                  Save logs before Claude Code deletes them\n")
+		fmt.Fprintf(os.Stderr, "  goccc -include-archive         All-time summary including archived logs\n\n")
 		fmt.Fprintf(os.Stderr, "Flags:\n")
 		flag.PrintDefaults()
 	}
 
 	flag.Parse()
+	// Arguments left after the flags are more files, which is also how a
+	// bare "-" for stdin arrives.
+	files = append(files, flag.Args()...)
 
 	if *showVersion {
 		fmt.Printf("goccc %s\n", version)
@@ -87,32 +150,206 @@ func main() {
 		color.NoColor = true
 	}
 
+	if len(baseDirs) == 0 {
+		baseDirs = sourcesFlag{{Label: ".claude", BaseDir: filepath.Join(homeDir, ".claude")}}
+	}
+	cacheDir := usage.DefaultCacheDir(baseDirs[0].BaseDir)
+	if *noCache {
+		cacheDir = ""
+	}
+
+	loc := time.Local
+	if *tz != "" {
+		if loc, err = time.LoadLocation(*tz); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -tz: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	offset, err := usage.ParseDayStart(*dayStart)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid -day-start: %v\n", err)
+		os.Exit(1)
+	}
+	clock := usage.Clock{Location: loc, DayStart: offset}
+
+	if *archiveDir != "" && len(baseDirs) > 1 {
+		fmt.Fprintf(os.Stderr, "Error: -archive-dir cannot be combined with several -base-dir; each uses its own <base-dir>/goccc-archive\n")
+		os.Exit(1)
+	}
+	sources := []usage.Source(baseDirs)
+	if *includeArchive {
+		for i := range sources {
+			sources[i].ArchiveDir = *archiveDir
+			if sources[i].ArchiveDir == "" {
+				sources[i].ArchiveDir = defaultArchiveDir(sources[i].BaseDir)
+			}
+		}
+	}
+
 	if *statusline {
-		runStatusline(*baseDir)
+		runStatusline(usage.Options{Sources: sources, Location: loc, DayStart: offset, Jobs: *jobs, CacheDir: cacheDir})
 		return
 	}
 
 	if *all {
-		*daily = true
-		*projects = true
+		for _, show := range []*bool{daily, weekly, monthly, projects, sessions, branches, agents, tools, versions, tiers, limits, heatmap} {
+			*show = true
+		}
+	}
+
+	if *period != "" {
+		p, err := usage.ParsePeriod(*period)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -period: %v\n", err)
+			os.Exit(1)
+		}
+		switch p {
+		case usage.PeriodDay:
+			*daily = true
+		case usage.PeriodWeek:
+			*weekly = true
+		case usage.PeriodMonth:
+			*monthly = true
+		}
+	}
+
+	weekday, err := usage.ParseWeekday(*weekStart)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid -week-start: %v\n", err)
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	var sinceTime, untilTime time.Time
+	if *since != "" {
+		if sinceTime, err = usage.ParseSince(*since, now, clock); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -since: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *until != "" {
+		if untilTime, err = usage.ParseUntil(*until, now, clock); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -until: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if !sinceTime.IsZero() && !untilTime.IsZero() && !sinceTime.Before(untilTime) {
+		fmt.Fprintf(os.Stderr, "Error: -since must be before -until\n")
+		os.Exit(1)
+	}
+
+	if *records != "" && *records != recordsNDJSON && *records != recordsCSV {
+		fmt.Fprintf(os.Stderr, "Error: invalid -records %q (want %s or %s)\n", *records, recordsNDJSON, recordsCSV)
+		os.Exit(1)
+	}
+
+	if _, err := path.Match(*branch, ""); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid -branch pattern %q: %v\n", *branch, err)
+		os.Exit(1)
+	}
+
+	regexes := make(map[string]*regexp.Regexp)
+	for name, expr := range map[string]string{
+		"project-regex":         *projectRegex,
+		"exclude-project-regex": *excludeProjectRegex,
+		"model-regex":           *modelRegex,
+	} {
+		if expr == "" {
+			continue
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -%s: %v\n", name, err)
+			os.Exit(1)
+		}
+		regexes[name] = re
+	}
+
+	if *watch {
+		var set []string
+		flag.Visit(func(f *flag.Flag) { set = append(set, f.Name) })
+		if bad := unsupportedWatchFlags(set, len(files)); len(bad) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: -watch does not support %s\n", strings.Join(bad, ", "))
+			os.Exit(1)
+		}
+		if *interval <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: -interval must be positive\n")
+			os.Exit(1)
+		}
+		runWatch(usage.Options{
+			Sources:             sources,
+			Location:            loc,
+			DayStart:            offset,
+			ProjectFilter:       *project,
+			BranchFilter:        *branch,
+			ProjectRegex:        regexes["project-regex"],
+			ExcludeProject:      *excludeProject,
+			ExcludeProjectRegex: regexes["exclude-project-regex"],
+			ModelFilter:         *model,
+			ModelRegex:          regexes["model-regex"],
+		}, *interval)
+		return
 	}
 
 	start := time.Now()
-	data, err := parseLogs(*baseDir, *days, *project)
+	data, err := usage.NewParser(usage.Options{
+		Sources:       sources,
+		Files:         files,
+		Days:          *days,
+		Since:         sinceTime,
+		Until:         untilTime,
+		Location:      loc,
+		DayStart:      offset,
+		ProjectFilter: *project,
+		BranchFilter:  *branch,
+		Tools:         *tools,
+		Records:       *records != "",
+
+		ProjectRegex:        regexes["project-regex"],
+		ExcludeProject:      *excludeProject,
+		ExcludeProjectRegex: regexes["exclude-project-regex"],
+		ModelFilter:         *model,
+		ModelRegex:          regexes["model-regex"],
+		Jobs:                *jobs,
+		CacheDir:            cacheDir,
+	}).Parse()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 	data.Duration = time.Since(start)
+	warnCacheError(data)
+
+	if *records != "" {
+		if err := printRecords(os.Stdout, data, *records, loc); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(strictExitCode(data, *strict))
+	}
 
-	if data.TotalRecords == 0 {
+	if data.TotalRecords == 0 && !*diagnostics {
 		fmt.Println("No usage data found.")
-		os.Exit(0)
+		os.Exit(strictExitCode(data, *strict))
 	}
 
 	opts := OutputOptions{
 		ShowDaily:    *daily,
+		ShowWeekly:   *weekly,
+		ShowMonthly:  *monthly,
+		Week:         usage.WeekOptions{Start: weekday, ISO: *isoWeek},
+		Location:     loc,
 		ShowProjects: *projects,
+		ShowSessions: *sessions,
+		ShowBranches: *branches,
+		ShowAgents:   *agents,
+		ShowTools:    *tools,
+		ShowVersions: *versions,
+		ShowTiers:    *tiers,
+		ShowSources:  len(data.Sources) > 1,
+		ShowLimits:   *limits,
+		ShowDiag:     *diagnostics,
+		ShowHeatmap:  *heatmap,
 		TopN:         *topN,
 	}
 
@@ -121,4 +358,22 @@ func main() {
 	} else {
 		printSummary(data, opts)
 	}
+	os.Exit(strictExitCode(data, *strict))
+}
+
+// warnCacheError reports a parse cache that could not be saved.
+func warnCacheError(data *usage.ParseResult) {
+	if data.CacheError != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not write parse cache: %v\n", data.CacheError)
+	}
+}
+
+// strictExitCode is the exit status for -strict: 3 when parsing hit any
+// problem -diagnostics would list, so scripts can tell it from a failed run.
+func strictExitCode(data *usage.ParseResult, strict bool) int {
+	if !strict || data.Diagnostics.Count() == 0 {
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "goccc: %d parse problems found (run with -diagnostics for details)\n", data.Diagnostics.Count())
+	return 3
 }
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/backstabslash/goccc/usage"
+)
+
+// Formats accepted by -records.
+const (
+	recordsNDJSON = "ndjson"
+	recordsCSV    = "csv"
+)
+
+// recordRow is one deduplicated request as exported by -records. Field order
+// is also the CSV column order. Cost is rounded to six decimal places to drop
+// float noise.
+type recordRow struct {
+	RequestID    string  `json:"request_id"`
+	Timestamp    string  `json:"timestamp"`
+	Project      string  `json:"project"` // working directory, or the slug when unknown
+	Slug         string  `json:"project_slug"`
+	SessionID    string  `json:"session_id"`
+	Model        string  `json:"model"`
+	Branch       string  `json:"branch"`
+	Subagent     bool    `json:"subagent"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CacheRead    int     `json:"cache_read_tokens"`
+	CacheWrite5m int     `json:"cache_write_5m_tokens"`
+	CacheWrite1h int     `json:"cache_write_1h_tokens"`
+	WebSearches  int     `json:"web_search_requests"`
+	WebFetches   int     `json:"web_fetch_requests"`
+	Cost         float64 `json:"cost"`
+}
+
+var recordColumns = []string{
+	"request_id", "timestamp", "project", "project_slug", "session_id", "model", "branch", "subagent",
+	"input_tokens", "output_tokens", "cache_read_tokens", "cache_write_5m_tokens", "cache_write_1h_tokens",
+	"web_search_requests", "web_fetch_requests", "cost",
+}
+
+func newRecordRow(data *usage.ParseResult, r *usage.Record, loc *time.Location) recordRow {
+	b := r.Bucket()
+	row := recordRow{
+		RequestID: r.RequestID, Project: r.Project, Slug: r.Project,
+		SessionID: r.Session, Model: r.Model, Branch: r.Branch, Subagent: r.Subagent,
+		InputTokens: b.InputTokens, OutputTokens: b.OutputTokens, CacheRead: b.CacheRead,
+		CacheWrite5m: b.CacheWrite5m, CacheWrite1h: b.CacheWrite1h,
+		WebSearches: b.WebSearches, WebFetches: b.WebFetches, Cost: math.Round(b.Cost*1e6) / 1e6,
+	}
+	if path := data.ProjectPaths[r.Project]; path != "" {
+		row.Project = path
+	}
+	if !r.Timestamp.IsZero() {
+		row.Timestamp = r.Timestamp.In(loc).Format(time.RFC3339Nano)
+	}
+	return row
+}
+
+func (row recordRow) csv() []string {
+	itoa := strconv.Itoa
+	return []string{
+		row.RequestID, row.Timestamp, row.Project, row.Slug, row.SessionID, row.Model, row.Branch,
+		strconv.FormatBool(row.Subagent), itoa(row.InputTokens), itoa(row.OutputTokens), itoa(row.CacheRead),
+		itoa(row.CacheWrite5m), itoa(row.CacheWrite1h), itoa(row.WebSearches), itoa(row.WebFetches),
+		strconv.FormatFloat(row.Cost, 'f', 6, 64),
+	}
+}
+
+// printRecords writes data.Records to w, one row per request, as NDJSON or
+// CSV with a header line.
+func printRecords(w io.Writer, data *usage.ParseResult, format string, loc *time.Location) error {
+	switch format {
+	case recordsNDJSON:
+		enc := json.NewEncoder(w)
+		for _, r := range data.Records {
+			if err := enc.Encode(newRecordRow(data, r, loc)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case recordsCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(recordColumns); err != nil {
+			return err
+		}
+		for _, r := range data.Records {
+			if err := cw.Write(newRecordRow(data, r, loc).csv()); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown records format %q (want %s or %s)", format, recordsNDJSON, recordsCSV)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/backstabslash/goccc/usage"
+)
+
+func recordsFixture() *usage.ParseResult {
+	return &usage.ParseResult{
+		ProjectPaths: map[string]string{"-home-me-app": "/home/me/app"},
+		Records: []*usage.Record{
+			{
+				RequestID: "req_1", Model: "claude-opus-4-6", Project: "-home-me-app", Session: "s1",
+				Timestamp: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+				Usage: usage.Usage{
+					InputTokens: 1000, OutputTokens: 500, CacheReadInputTokens: 2000,
+					CacheCreation: &usage.CacheCreation{Ephemeral5mInputTokens: 300, Ephemeral1hInputTokens: 400},
+				},
+			},
+			{Model: "claude-haiku-4-5", Project: "-tmp-x", Session: "s2", Subagent: true, Usage: usage.Usage{InputTokens: 10}},
+		},
+	}
+}
+
+func TestPrintRecords_NDJSON(t *testing.T) {
+	data := recordsFixture()
+	var buf bytes.Buffer
+	if err := printRecords(&buf, data, recordsNDJSON, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	var row recordRow
+	if err := json.Unmarshal([]byte(lines[0]), &row); err != nil {
+		t.Fatal(err)
+	}
+	if row.RequestID != "req_1" || row.Project != "/home/me/app" || row.Slug != "-home-me-app" ||
+		row.Timestamp != "2026-03-02T10:00:00Z" || row.CacheWrite5m != 300 || row.CacheWrite1h != 400 {
+		t.Errorf("row = %+v", row)
+	}
+	if want := data.Records[0].Bucket().Cost; math.Abs(row.Cost-want) > 1e-6 {
+		t.Errorf("cost = %v, want %v", row.Cost, want)
+	}
+
+	if err := json.Unmarshal([]byte(lines[1]), &row); err != nil {
+		t.Fatal(err)
+	}
+	if row.RequestID != "" || row.Timestamp != "" || row.Project != "-tmp-x" || !row.Subagent {
+		t.Errorf("row without id, timestamp or cwd = %+v", row)
+	}
+}
+
+func TestPrintRecords_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printRecords(&buf, recordsFixture(), recordsCSV, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want header + 2", len(rows))
+	}
+	for i, row := range rows {
+		if len(row) != len(recordColumns) {
+			t.Errorf("row %d has %d columns, want %d", i, len(row), len(recordColumns))
+		}
+	}
+	if got := strings.Join(rows[1][:6], ","); got != "req_1,2026-03-02T10:00:00Z,/home/me/app,-home-me-app,s1,claude-opus-4-6" {
+		t.Errorf("row 1 = %s", got)
+	}
+
+	if err := printRecords(&buf, recordsFixture(), "xml", time.UTC); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestPrintRecords_CostPrecision(t *testing.T) {
+	data := &usage.ParseResult{Records: []*usage.Record{{
+		RequestID: "req_1", Model: "claude-opus-4-6",
+		Usage: usage.Usage{InputTokens: 22000, OutputTokens: 4800, CacheReadInputTokens: 62000,
+			CacheCreation: &usage.CacheCreation{Ephemeral1hInputTokens: 5000}},
+	}}}
+	var buf bytes.Buffer
+	if err := printRecords(&buf, data, recordsCSV, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), ",0.311000\n") {
+		t.Errorf("cost not written at fixed precision:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := printRecords(&buf, data, recordsNDJSON, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"cost":0.311}`) {
+		t.Errorf("cost not rounded:\n%s", buf.String())
+	}
+}
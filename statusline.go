@@ -5,10 +5,9 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"strings"
-	"time"
 
+	"github.com/backstabslash/goccc/usage"
 	"github.com/fatih/color"
 )
 
@@ -39,45 +38,6 @@ func readStatuslineInput(r io.Reader) (*StatuslineInput, error) {
 	return &input, nil
 }
 
-func parseSession(transcriptPath string) (map[string]*dedupRecord, error) {
-	deduped := make(map[string]*dedupRecord)
-
-	if _, _, err := parseFile(transcriptPath, time.Time{}, false, "", deduped); err != nil {
-		return nil, fmt.Errorf("parsing transcript: %w", err)
-	}
-
-	base := strings.TrimSuffix(transcriptPath, ".jsonl")
-	subagentDir := filepath.Join(base, "subagents")
-
-	entries, err := os.ReadDir(subagentDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return deduped, nil
-		}
-		return nil, err
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
-			continue
-		}
-		path := filepath.Join(subagentDir, entry.Name())
-		if _, _, err := parseFile(path, time.Time{}, false, "", deduped); err != nil {
-			fmt.Fprintf(os.Stderr, "goccc: warning: subagent %s: %v\n", entry.Name(), err)
-		}
-	}
-
-	return deduped, nil
-}
-
-func sessionCost(deduped map[string]*dedupRecord) float64 {
-	var total float64
-	for _, r := range deduped {
-		total += calcCost(r.Model, r.Usage)
-	}
-	return total
-}
-
 func formatStatusline(sCost, tCost float64, input *StatuslineInput) string {
 	ctxPct := input.ContextWindow.UsedPercentage
 	ctxStr := fmt.Sprintf("%.0f%% ctx", ctxPct)
@@ -90,7 +50,7 @@ func formatStatusline(sCost, tCost float64, input *StatuslineInput) string {
 		ctxStr = color.GreenString(ctxStr)
 	}
 
-	modelStr := color.CyanString(shortModel(input.Model.ID))
+	modelStr := color.CyanString(usage.ModelName(input.Model.ID))
 
 	parts := []string{"💸 " + colorCost(sCost, 0) + " session"}
 	if tCost > 0 && tCost != sCost {
@@ -101,7 +61,7 @@ func formatStatusline(sCost, tCost float64, input *StatuslineInput) string {
 	return strings.Join(parts, " | ")
 }
 
-func runStatusline(baseDir string) {
+func runStatusline(opts usage.Options) {
 	input, err := readStatuslineInput(os.Stdin)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "goccc: %v\n", err)
@@ -110,9 +70,12 @@ func runStatusline(baseDir string) {
 
 	var sCost float64
 	if input.TranscriptPath != "" {
-		deduped, err := parseSession(input.TranscriptPath)
+		cost, warnings, err := usage.NewParser(opts).SessionCost(input.TranscriptPath)
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "goccc: warning: %v\n", w)
+		}
 		if err == nil {
-			sCost = sessionCost(deduped)
+			sCost = cost
 		} else {
 			sCost = input.Cost.TotalCostUSD
 		}
@@ -121,9 +84,11 @@ func runStatusline(baseDir string) {
 	}
 
 	var tCost float64
-	todayData, err := parseLogs(baseDir, 1, "")
+	opts.Days = 1
+	todayData, err := usage.NewParser(opts).Parse()
 	if err == nil {
 		tCost = todayData.Totals().Cost
+		warnCacheError(todayData)
 	}
 
 	fmt.Print(formatStatusline(sCost, tCost, input))
@@ -0,0 +1,81 @@
+package usage
+
+import "encoding/json"
+
+// Subagents are spawned by Task tool calls whose input names the subagent
+// type. Once the subagent finishes, the parent transcript records a tool
+// result carrying its agentId. Linking the two labels the subagent's own
+// transcript with its type.
+
+type contentBlock struct {
+	Type      string          `json:"type"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+}
+
+// parseTaskCalls records the subagent type of every Task tool call in an
+// assistant message.
+func parseTaskCalls(line []byte, data *fileData) {
+	var rec struct {
+		Message struct {
+			Content []contentBlock `json:"content"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return
+	}
+	for _, c := range rec.Message.Content {
+		if c.Type != "tool_use" || c.ID == "" || len(c.Input) == 0 {
+			continue
+		}
+		var input struct {
+			SubagentType string `json:"subagent_type"`
+		}
+		if err := json.Unmarshal(c.Input, &input); err == nil && input.SubagentType != "" {
+			data.TaskTypes[c.ID] = input.SubagentType
+		}
+	}
+}
+
+// parseTaskResult links the agentId reported by a Task tool result to the
+// tool call that spawned it. toolUseResult and content take several shapes
+// across Claude Code versions; anything unexpected is ignored.
+func parseTaskResult(line []byte, data *fileData) {
+	var rec struct {
+		ToolUseResult json.RawMessage `json:"toolUseResult"`
+		Message       struct {
+			Content json.RawMessage `json:"content"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return
+	}
+	var result struct {
+		AgentID string `json:"agentId"`
+	}
+	if err := json.Unmarshal(rec.ToolUseResult, &result); err != nil || result.AgentID == "" {
+		return
+	}
+	var blocks []contentBlock
+	if err := json.Unmarshal(rec.Message.Content, &blocks); err != nil {
+		return
+	}
+	for _, c := range blocks {
+		if c.Type == "tool_result" && c.ToolUseID != "" {
+			data.TaskAgents[result.AgentID] = c.ToolUseID
+			return
+		}
+	}
+}
+
+// resolveAgentTypes adds the subagent type of every agent spawned from this
+// file to dst.
+func (d *fileData) resolveAgentTypes(dst map[string]string) {
+	for agentID, toolID := range d.TaskAgents {
+		if t, ok := d.TaskTypes[toolID]; ok {
+			dst[agentID] = t
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package usage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func makeTaskCall(requestID, toolUseID, subagentType, timestamp string) string {
+	rec := makeRecord(requestID, "claude-opus-4-6", timestamp, 100, 50, 0, 0, 0)
+	content := fmt.Sprintf(`"content":[{"type":"tool_use","id":%q,"name":"Task","input":{"description":"search","prompt":"find it","subagent_type":%q}}],`, toolUseID, subagentType)
+	return strings.Replace(rec, `"role":"assistant",`, `"role":"assistant",`+content, 1)
+}
+
+func makeTaskResult(toolUseID, agentID, timestamp string) string {
+	return fmt.Sprintf(`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":%q,"content":"done"}]},"toolUseResult":{"status":"completed","agentId":%q},"timestamp":%q}`,
+		toolUseID, agentID, timestamp)
+}
+
+func TestAgentTypes_LinkedFromTaskCalls(t *testing.T) {
+	base := t.TempDir()
+	projDir := filepath.Join(base, "projects", "test-project")
+	subDir := filepath.Join(projDir, "sess-1", "subagents")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := strings.Join([]string{
+		makeTaskCall("req_main_1", "toolu_1", "Explore", ts(0, 10)),
+		makeTaskResult("toolu_1", "aaa111", ts(0, 11)),
+		makeTaskCall("req_main_2", "toolu_2", "code-reviewer", ts(0, 12)),
+		// A plain string result must not break parsing.
+		`{"type":"user","message":{"role":"user","content":"hi"},"toolUseResult":"Error: agentId missing","timestamp":"` + ts(0, 12) + `"}`,
+	}, "\n") + "\n"
+	explore := makeRecord("req_sub_1", "claude-haiku-4-5-20251001", ts(0, 10), 500, 100, 0, 0, 0) + "\n"
+	// No tool result links this agent, so its type stays unknown.
+	unlinked := makeRecord("req_sub_2", "claude-haiku-4-5-20251001", ts(0, 12), 500, 100, 0, 0, 0) + "\n"
+
+	for path, content := range map[string]string{
+		filepath.Join(projDir, "sess-1.jsonl"):      parent,
+		filepath.Join(subDir, "agent-aaa111.jsonl"): explore,
+		filepath.Join(subDir, "agent-bbb222.jsonl"): unlinked,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "ParseErrors", data.ParseErrors, 0)
+
+	haiku := "claude-haiku-4-5-20251001"
+	if b := data.AgentTypeUsage["Explore"][haiku]; b == nil || b.Requests != 1 {
+		t.Errorf("Explore bucket = %+v, want 1 request", b)
+	}
+	if b := data.AgentTypeUsage[""][haiku]; b == nil || b.Requests != 1 {
+		t.Errorf("unknown agent type bucket = %+v, want 1 request", b)
+	}
+	if _, ok := data.AgentTypeUsage["code-reviewer"]; ok {
+		t.Error("agent type without a linked transcript should not appear")
+	}
+
+	mainThread, subagents := data.ThreadTotals()
+	assertInt(t, "main thread requests", mainThread.Requests, 2)
+	assertInt(t, "subagent requests", subagents.Requests, 2)
+
+	threads := data.SessionUsage["sess-1"].Threads
+	assertInt(t, "session subagent requests", threads[ThreadSubagent].Requests, 2)
+}
+
+func TestSidechainRecordsCountAsSubagent(t *testing.T) {
+	sidechain := strings.Replace(makeRecord("req_1", "claude-haiku-4-5-20251001", ts(0, 10), 100, 50, 0, 0, 0),
+		`"type":"assistant"`, `"type":"assistant","isSidechain":true`, 1)
+	base := setupProject(t, "test-project", []string{
+		sidechain,
+		makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 100, 50, 0, 0, 0),
+	})
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	threads := data.ThreadUsage["test-project"]
+	assertInt(t, "main requests", threads[ThreadMain].Requests, 1)
+	assertInt(t, "subagent requests", threads[ThreadSubagent].Requests, 1)
+}
@@ -0,0 +1,107 @@
+package usage
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ArchiveStats counts what ArchiveLogs did with each live log file.
+type ArchiveStats struct {
+	Added     int
+	Updated   int
+	Unchanged int
+	Errors    []error // one per file or directory that could not be archived
+}
+
+// ArchiveLogs copies every log under projectsDir into archiveDir/projects,
+// gzip-compressed and in the same <slug>/... layout. Each copy takes the
+// modification time of its source, which is how later runs tell unchanged
+// files apart: only new and changed logs are written again. Archived copies
+// of logs Claude Code has since deleted are kept.
+func ArchiveLogs(projectsDir, archiveDir string) (ArchiveStats, error) {
+	var stats ArchiveStats
+	dest := filepath.Join(archiveDir, "projects")
+	err := filepath.WalkDir(projectsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			stats.Errors = append(stats.Errors, err)
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(d.Name()) != ".jsonl" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			stats.Errors = append(stats.Errors, err)
+			return nil
+		}
+		rel, err := filepath.Rel(projectsDir, path)
+		if err != nil {
+			return nil
+		}
+		target := filepath.Join(dest, rel+gzipExt)
+
+		prev, statErr := os.Stat(target)
+		if statErr == nil && sameModTime(prev.ModTime(), info.ModTime()) {
+			stats.Unchanged++
+			return nil
+		}
+		if err := archiveFile(path, target, info.ModTime()); err != nil {
+			stats.Errors = append(stats.Errors, fmt.Errorf("could not archive %s: %w", path, err))
+		} else if statErr == nil {
+			stats.Updated++
+		} else {
+			stats.Added++
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// sameModTime compares modification times at second precision, which is
+// all some filesystems keep.
+func sameModTime(a, b time.Time) bool {
+	return a.Truncate(time.Second).Equal(b.Truncate(time.Second))
+}
+
+// archiveFile writes a gzip copy of src to dst atomically, so an interrupted
+// run never leaves a truncated archive behind, and stamps it with modTime.
+func archiveFile(src, dst string, modTime time.Time) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	zw := gzip.NewWriter(tmp)
+	zw.Name = filepath.Base(src)
+	zw.ModTime = modTime
+	if _, err := io.Copy(zw, in); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, modTime, modTime)
+}
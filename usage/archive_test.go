@@ -0,0 +1,107 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestArchiveLogs_Idempotent(t *testing.T) {
+	base := setupProject(t, "test-project", []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	projectsDir := filepath.Join(base, "projects")
+	sub := filepath.Join(projectsDir, "test-project", "session", "subagents", "agent-a1.jsonl")
+	if err := os.MkdirAll(filepath.Dir(sub), 0755); err != nil {
+		t.Fatal(err)
+	}
+	line := makeRecord("req_sub", "claude-haiku-4-5", ts(0, 10), 10, 5, 0, 0, 0) + "\n"
+	if err := os.WriteFile(sub, []byte(line), 0644); err != nil {
+		t.Fatal(err)
+	}
+	archiveDir := filepath.Join(base, "goccc-archive")
+
+	stats, err := ArchiveLogs(projectsDir, archiveDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stats, ArchiveStats{Added: 2}) {
+		t.Errorf("first run = %+v, want 2 added", stats)
+	}
+	for _, rel := range []string{
+		"test-project/session.jsonl.gz",
+		"test-project/session/subagents/agent-a1.jsonl.gz",
+	} {
+		if _, err := os.Stat(filepath.Join(archiveDir, "projects", filepath.FromSlash(rel))); err != nil {
+			t.Errorf("archive missing %s: %v", rel, err)
+		}
+	}
+
+	stats, err = ArchiveLogs(projectsDir, archiveDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stats, ArchiveStats{Unchanged: 2}) {
+		t.Errorf("second run = %+v, want 2 unchanged", stats)
+	}
+
+	session := filepath.Join(projectsDir, "test-project", "session.jsonl")
+	appendLines(t, session, makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 100, 50, 0, 0, 0)+"\n")
+	bumpModTime(t, session)
+	stats, err = ArchiveLogs(projectsDir, archiveDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stats, ArchiveStats{Updated: 1, Unchanged: 1}) {
+		t.Errorf("after append = %+v, want 1 updated, 1 unchanged", stats)
+	}
+}
+
+func TestIncludeArchive(t *testing.T) {
+	base := setupProject(t, "test-project", []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 100, 50, 0, 0, 0),
+	})
+	projectsDir := filepath.Join(base, "projects")
+	archiveDir := filepath.Join(base, "goccc-archive")
+	if _, err := ArchiveLogs(projectsDir, archiveDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Live and archived copies of the same log count once.
+	data, err := parseLogs(Options{BaseDir: base, ArchiveDir: archiveDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalRecords with live copy", data.TotalRecords, 2)
+
+	// Once Claude Code cleans the log up, only the archive has it.
+	if err := os.Remove(filepath.Join(projectsDir, "test-project", "session.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	data, err = parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalRecords without archive", data.TotalRecords, 0)
+	data, err = parseLogs(Options{BaseDir: base, ArchiveDir: archiveDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalRecords from archive", data.TotalRecords, 2)
+	if data.SessionUsage["session"] == nil {
+		t.Error("archived session missing")
+	}
+}
+
+func TestIncludeArchive_MissingArchiveIsEmpty(t *testing.T) {
+	base := setupProject(t, "test-project", []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	data, err := parseLogs(Options{BaseDir: base, ArchiveDir: filepath.Join(base, "nope")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalRecords", data.TotalRecords, 1)
+}
@@ -0,0 +1,199 @@
+package usage
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Bump cacheVersion whenever fileData, fileState or the parse semantics
+// change, so stale entries are discarded instead of misread.
+const cacheVersion = 15
+
+// cacheDirName is the directory under Options.CacheDir holding the entries.
+const cacheDirName = "parse-cache"
+
+// legacyCacheFileName is the single-file cache of earlier versions, which
+// held every entry and is removed when found.
+const legacyCacheFileName = "parse-cache.gob"
+
+// parseCache persists the parsed data of each log file between runs, one
+// file per log, so a run only decodes and writes the entries of the logs it
+// selects. Entries are grouped by the projects directory the log was found
+// in, and keyed by its absolute path. An entry is reused as-is while the
+// file's size and mtime are unchanged, and resumed from its offset when the
+// file has grown.
+type parseCache struct {
+	dir     string
+	pricing string
+}
+
+type cacheEntry struct {
+	Version int
+	Pricing string
+	Path    string // the log's path, which the entry's file name only hashes
+	Size    int64
+	ModTime time.Time
+	State   fileState
+	Data    *fileData
+}
+
+// DefaultCacheDir is where the parse cache lives unless told otherwise: a
+// goccc directory in the user cache directory, or baseDir/goccc-cache when
+// the platform has none.
+func DefaultCacheDir(baseDir string) string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "goccc")
+	}
+	return filepath.Join(baseDir, "goccc-cache")
+}
+
+// pricingFingerprint identifies the pricing rules the cache was written with.
+func pricingFingerprint() string {
+	h := sha256.New()
+	models := make([]string, 0, len(pricingTable))
+	for m := range pricingTable {
+		models = append(models, m)
+	}
+	sort.Strings(models)
+	for _, m := range models {
+		fmt.Fprintf(h, "%s=%+v;", m, pricingTable[m])
+	}
+	for _, fp := range familyPrefixes {
+		fmt.Fprintf(h, "%s>%s;", fp.Prefix, fp.Key)
+	}
+	tiers := make([]string, 0, len(standardTierMultipliers))
+	for t := range standardTierMultipliers {
+		tiers = append(tiers, t)
+	}
+	sort.Strings(tiers)
+	for _, t := range tiers {
+		fmt.Fprintf(h, "%s*%g;", t, standardTierMultipliers[t])
+	}
+	tools := make([]string, 0, len(serverToolPricing))
+	for t := range serverToolPricing {
+		tools = append(tools, t)
+	}
+	sort.Strings(tools)
+	for _, t := range tools {
+		fmt.Fprintf(h, "%s$%g;", t, serverToolPricing[t])
+	}
+	fmt.Fprintf(h, "default=%+v", defaultPricing)
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// openCache returns the cache kept in dir.
+func openCache(dir string) *parseCache {
+	return &parseCache{dir: filepath.Join(dir, cacheDirName), pricing: pricingFingerprint()}
+}
+
+// hashName turns a path into a file name.
+func hashName(path string) string {
+	h := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(h[:])[:16]
+}
+
+// entryPath returns where the entry of f is stored, or "" for logs that are
+// not cached: those outside a projects directory.
+func (c *parseCache) entryPath(f logFile) string {
+	if c == nil || f.root == "" {
+		return ""
+	}
+	return filepath.Join(c.dir, hashName(f.root), hashName(f.path)+".gob")
+}
+
+// lookup returns the entry of f. A missing, unreadable or outdated entry
+// yields nil.
+func (c *parseCache) lookup(f logFile) *cacheEntry {
+	path := c.entryPath(f)
+	if path == "" {
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = file.Close() }()
+
+	var e cacheEntry
+	if err := gob.NewDecoder(bufio.NewReader(file)).Decode(&e); err != nil {
+		return nil
+	}
+	if e.Version != cacheVersion || e.Pricing != c.pricing || e.Path != f.path || e.Data == nil {
+		return nil
+	}
+	return &e
+}
+
+// store writes the entry of f atomically, so concurrent runs (e.g.
+// statusline refreshes) never observe a partial file.
+func (c *parseCache) store(f logFile, e *cacheEntry) error {
+	path := c.entryPath(f)
+	if path == "" {
+		return nil
+	}
+	e.Version, e.Pricing, e.Path = cacheVersion, c.pricing, f.path
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	w := bufio.NewWriter(tmp)
+	if err := gob.NewEncoder(w).Encode(e); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// prune drops the entries of files under root that no longer exist. It must
+// only be called after an unfiltered walk, when files lists everything
+// under root.
+func (c *parseCache) prune(root string, files []logFile) error {
+	_ = os.Remove(filepath.Join(filepath.Dir(c.dir), legacyCacheFileName))
+
+	keep := make(map[string]bool, len(files))
+	for _, f := range files {
+		if f.root == root {
+			keep[filepath.Base(c.entryPath(f))] = true
+		}
+	}
+	dir := filepath.Join(c.dir, hashName(root))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var errs []error
+	for _, e := range entries {
+		// Temporary files belong to a store in progress.
+		if strings.HasSuffix(e.Name(), ".gob") && !keep[e.Name()] {
+			if err := os.Remove(filepath.Join(dir, e.Name())); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
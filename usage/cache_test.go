@@ -0,0 +1,256 @@
+package usage
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func appendLines(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	for _, l := range lines {
+		if _, err := f.WriteString(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// bumpModTime makes sure a rewritten file doesn't share its mtime with the
+// cached entry on filesystems with coarse timestamps.
+func bumpModTime(t *testing.T, path string) {
+	t.Helper()
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCache_ReusesAndResumes(t *testing.T) {
+	base := setupProject(t, "test-project", []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 200, 50, 0, 0, 0),
+	})
+	cacheDir := t.TempDir()
+	opts := Options{BaseDir: base, CacheDir: cacheDir}
+
+	first, err := parseLogs(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countCacheEntries(t, cacheDir); n != 1 {
+		t.Fatalf("cache holds %d entries, want 1", n)
+	}
+
+	second, err := parseLogs(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "cached TotalRecords", second.TotalRecords, first.TotalRecords)
+	assertCost(t, "cached cost", second.Totals().Cost, first.Totals().Cost)
+
+	// Append a record; only the new bytes should be parsed on the next run.
+	path := filepath.Join(base, "projects", "test-project", "session.jsonl")
+	appendLines(t, path, makeRecord("req_3", "claude-sonnet-4-6", ts(0, 12), 300, 50, 0, 0, 0)+"\n")
+	bumpModTime(t, path)
+
+	third, err := parseLogs(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "resumed TotalRecords", third.TotalRecords, 3)
+	assertInt(t, "resumed opus input", third.ModelUsage["claude-opus-4-6"].InputTokens, 300)
+	assertInt(t, "resumed sonnet input", third.ModelUsage["claude-sonnet-4-6"].InputTokens, 300)
+
+	uncached, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertCost(t, "resumed cost", third.Totals().Cost, uncached.Totals().Cost)
+}
+
+func TestCache_PartialTrailingLine(t *testing.T) {
+	base := setupProject(t, "test-project", []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	cacheDir := t.TempDir()
+	opts := Options{BaseDir: base, CacheDir: cacheDir}
+
+	path := filepath.Join(base, "projects", "test-project", "session.jsonl")
+	line := makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 200, 50, 0, 0, 0)
+	appendLines(t, path, line[:len(line)/2])
+
+	data, err := parseLogs(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "partial TotalRecords", data.TotalRecords, 1)
+	assertInt(t, "partial ParseErrors", data.ParseErrors, 1)
+
+	// Unchanged, the cut-off line is still reported from the cache.
+	data, err = parseLogs(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "cached partial ParseErrors", data.ParseErrors, 1)
+
+	appendLines(t, path, line[len(line)/2:]+"\n")
+	bumpModTime(t, path)
+
+	data, err = parseLogs(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "completed TotalRecords", data.TotalRecords, 2)
+	assertInt(t, "completed ParseErrors", data.ParseErrors, 0)
+}
+
+func TestCache_DaysFilterAppliedToCachedRecords(t *testing.T) {
+	base := setupProject(t, "test-project", []string{
+		makeRecord("req_today", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		makeRecord("req_old", "claude-opus-4-6", ts(5, 10), 100, 50, 0, 0, 0),
+	})
+	cacheDir := t.TempDir()
+
+	all, err := parseLogs(Options{BaseDir: base, CacheDir: cacheDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "all-time TotalRecords", all.TotalRecords, 2)
+
+	today, err := parseLogs(Options{BaseDir: base, Days: 1, CacheDir: cacheDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "today TotalRecords", today.TotalRecords, 1)
+}
+
+func TestCache_InvalidatedByVersionAndPricing(t *testing.T) {
+	dir := t.TempDir()
+	f := logFile{path: "/some/projects/p/file.jsonl", root: "/some/projects"}
+	c := openCache(dir)
+	if err := c.store(f, &cacheEntry{Size: 1, Data: newFileData()}); err != nil {
+		t.Fatal(err)
+	}
+	if openCache(dir).lookup(f) == nil {
+		t.Fatal("stored entry not found")
+	}
+	if openCache(dir).lookup(logFile{path: "/some/projects/p/other.jsonl", root: f.root}) != nil {
+		t.Error("entry found for another file")
+	}
+
+	stale := openCache(dir)
+	stale.pricing = "stale"
+	if err := stale.store(f, &cacheEntry{Size: 1, Data: newFileData()}); err != nil {
+		t.Fatal(err)
+	}
+	if openCache(dir).lookup(f) != nil {
+		t.Error("entry with stale pricing was used")
+	}
+
+	// store always writes the current version, so write an old one by hand.
+	out, err := os.Create(c.entryPath(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := cacheEntry{Version: cacheVersion - 1, Pricing: c.pricing, Path: f.path, Data: newFileData()}
+	if err := gob.NewEncoder(out).Encode(&old); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if openCache(dir).lookup(f) != nil {
+		t.Error("entry of another version was used")
+	}
+}
+
+func TestCache_PrunesDeletedFiles(t *testing.T) {
+	base := setupProject(t, "project-a", []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	addProject(t, base, "project-b", []string{
+		makeRecord("req_2", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	cacheDir := t.TempDir()
+	opts := Options{BaseDir: base, CacheDir: cacheDir}
+
+	if _, err := parseLogs(opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(base, "projects", "project-b")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseLogs(opts); err != nil {
+		t.Fatal(err)
+	}
+	if got := countCacheEntries(t, cacheDir); got != 1 {
+		t.Errorf("cache has %d entries after deleting a project, want 1", got)
+	}
+}
+
+func TestCache_RemovesLegacyFile(t *testing.T) {
+	base := setupProject(t, "test-project", []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	cacheDir := t.TempDir()
+	legacy := filepath.Join(cacheDir, legacyCacheFileName)
+	if err := os.WriteFile(legacy, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseLogs(Options{BaseDir: base, CacheDir: cacheDir}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("legacy cache file still present: %v", err)
+	}
+}
+
+func TestCache_SharedWithSessionCost(t *testing.T) {
+	base := setupProject(t, "test-project", []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	path := filepath.Join(base, "projects", "test-project", "session.jsonl")
+	cacheDir := t.TempDir()
+	opts := Options{BaseDir: base, CacheDir: cacheDir}
+	if _, err := parseLogs(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	// The session grows; SessionCost resumes the entry Parse wrote, and
+	// Parse then reuses the one SessionCost wrote.
+	appendLines(t, path, makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 200, 50, 0, 0, 0)+"\n")
+	bumpModTime(t, path)
+	cost, _, err := NewParser(opts).SessionCost(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "cache entries", countCacheEntries(t, cacheDir), 1)
+	e := openCache(cacheDir).lookup(logFile{path: path, root: filepath.Join(base, "projects")})
+	if e == nil || len(e.Data.Records) != 2 {
+		t.Fatalf("entry after SessionCost = %+v, want 2 records", e)
+	}
+
+	data, err := parseLogs(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalRecords", data.TotalRecords, 2)
+	assertCost(t, "session cost", cost, data.Totals().Cost)
+}
+
+// countCacheEntries counts the entry files in the cache under cacheDir.
+func countCacheEntries(t *testing.T, cacheDir string) int {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(cacheDir, cacheDirName, "*", "*.gob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(matches)
+}
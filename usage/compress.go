@@ -0,0 +1,88 @@
+package usage
+
+import (
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression suffixes recognized after .jsonl, for archived logs.
+const (
+	gzipExt = ".gz"
+	zstdExt = ".zst"
+)
+
+// logBaseName strips the compression suffix from a log file name, so
+// session.jsonl.gz names the same log as session.jsonl. ok reports whether
+// name is a log file at all.
+func logBaseName(name string) (base string, ok bool) {
+	base = strings.TrimSuffix(strings.TrimSuffix(name, gzipExt), zstdExt)
+	return base, strings.HasSuffix(base, ".jsonl") && (base == name || isCompressed(name))
+}
+
+func isCompressed(name string) bool {
+	return strings.HasSuffix(name, ".jsonl"+gzipExt) || strings.HasSuffix(name, ".jsonl"+zstdExt)
+}
+
+// openLog opens a log file for reading, decompressing it when its name
+// says it is compressed. stdinPath reads standard input.
+func openLog(path string) (io.ReadCloser, error) {
+	if path == stdinPath {
+		return io.NopCloser(os.Stdin), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return decompress(f, path)
+}
+
+// openFSLog is openLog for a log inside an fs.FS.
+func openFSLog(fsys fs.FS, name string) (io.ReadCloser, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return decompress(f, name)
+}
+
+// decompress wraps f in a decompressing reader when name has a compression
+// suffix, and returns it unchanged otherwise. f is closed on error.
+func decompress(f io.ReadCloser, name string) (io.ReadCloser, error) {
+	switch {
+	case strings.HasSuffix(name, gzipExt):
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return &decompressor{Reader: zr, close: zr.Close, file: f}, nil
+	case strings.HasSuffix(name, zstdExt):
+		zr, err := zstd.NewReader(f, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		return &decompressor{Reader: zr, close: func() error { zr.Close(); return nil }, file: f}, nil
+	}
+	return f, nil
+}
+
+// decompressor closes both the decompressing reader and the file under it.
+type decompressor struct {
+	io.Reader
+	close func() error
+	file  io.Closer
+}
+
+func (d *decompressor) Close() error {
+	err := d.close()
+	if fErr := d.file.Close(); err == nil {
+		err = fErr
+	}
+	return err
+}
@@ -0,0 +1,151 @@
+package usage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// writeCompressed writes lines to path, compressed according to its suffix.
+func writeCompressed(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	var buf bytes.Buffer
+	content := []byte(strings.Join(lines, "\n") + "\n")
+	switch filepath.Ext(path) {
+	case gzipExt:
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	case zstdExt:
+		zw, err := zstd.NewWriter(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := zw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatalf("unknown compression for %s", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogBaseName(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		ok   bool
+	}{
+		{"session.jsonl", "session.jsonl", true},
+		{"session.jsonl.gz", "session.jsonl", true},
+		{"session.jsonl.zst", "session.jsonl", true},
+		{"session.json", "session.json", false},
+		{"notes.gz", "notes", false},
+		{"session.jsonl.zst.gz", "session.jsonl", false},
+	}
+	for _, tt := range tests {
+		base, ok := logBaseName(tt.name)
+		if base != tt.base || ok != tt.ok {
+			t.Errorf("logBaseName(%q) = %q, %v; want %q, %v", tt.name, base, ok, tt.base, tt.ok)
+		}
+	}
+}
+
+func TestSourceForPath_Compressed(t *testing.T) {
+	src := sourceForPath(filepath.Join("proj", "sess-1.jsonl.gz"))
+	if src.Session != "sess-1" || !src.Compressed {
+		t.Errorf("got %+v, want session sess-1, compressed", src)
+	}
+	src = sourceForPath(filepath.Join("proj", "sess-1", "subagents", "agent-a1.jsonl.zst"))
+	if src.Session != "sess-1" || src.Agent != "a1" || !src.Subagent || !src.Compressed {
+		t.Errorf("got %+v, want subagent a1 of sess-1, compressed", src)
+	}
+}
+
+func TestCompressedLogs(t *testing.T) {
+	base := setupProject(t, "test-project", []string{
+		makeRecord("req_plain", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	dir := filepath.Join(base, "projects", "test-project")
+	writeCompressed(t, filepath.Join(dir, "old-1.jsonl.gz"),
+		makeRecord("req_gz", "claude-opus-4-6", ts(1, 10), 100, 50, 0, 0, 0))
+	writeCompressed(t, filepath.Join(dir, "old-2.jsonl.zst"),
+		makeRecord("req_zst", "claude-opus-4-6", ts(2, 10), 100, 50, 0, 0, 0))
+
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalFiles", data.TotalFiles, 3)
+	assertInt(t, "TotalRecords", data.TotalRecords, 3)
+	for _, s := range []string{"old-1", "old-2"} {
+		if data.SessionUsage[s] == nil {
+			t.Errorf("session %s from compressed log missing", s)
+		}
+	}
+}
+
+// An archive next to the log it was made from must not double count, and
+// the live log, which may have grown since, wins.
+func TestCompressedLogs_DedupWithLiveCopy(t *testing.T) {
+	archived := []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		`{"type":"assistant","timestamp":"` + ts(0, 11) + `","message":{"model":"claude-opus-4-6","role":"assistant","usage":{"input_tokens":10,"output_tokens":5}}}`,
+	}
+	live := []string{
+		archived[0],
+		`{"type":"assistant","timestamp":"` + ts(0, 11) + `","message":{"model":"claude-opus-4-6","role":"assistant","usage":{"input_tokens":10,"output_tokens":500}}}`,
+	}
+	base := setupProject(t, "test-project", live)
+	dir := filepath.Join(base, "projects", "test-project")
+	writeCompressed(t, filepath.Join(dir, "session.jsonl.gz"), archived...)
+	writeCompressed(t, filepath.Join(dir, "session.jsonl.zst"), archived...)
+
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalRecords", data.TotalRecords, 2)
+	assertInt(t, "OutputTokens", data.ModelUsage["claude-opus-4-6"].OutputTokens, 550)
+}
+
+func TestCompressedLogs_CachedAndReparsed(t *testing.T) {
+	base := t.TempDir()
+	path := filepath.Join(base, "projects", "test-project", "session.jsonl.gz")
+	writeCompressed(t, path, makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0))
+	opts := Options{BaseDir: base, CacheDir: t.TempDir()}
+
+	if _, err := parseLogs(opts); err != nil {
+		t.Fatal(err)
+	}
+	writeCompressed(t, path,
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 100, 50, 0, 0, 0))
+	bumpModTime(t, path)
+
+	data, err := parseLogs(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalRecords", data.TotalRecords, 2)
+	if n := data.Diagnostics.Count(); n != 0 {
+		t.Errorf("got %d diagnostics, want 0: %+v", n, data.Diagnostics.Issues)
+	}
+}
@@ -0,0 +1,120 @@
+package usage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// Clock assigns timestamps to report days: calendar days in Location that
+// begin DayStart after midnight, so with a 04:00 boundary late-night work
+// counts toward the previous day. The zero value uses local midnight.
+type Clock struct {
+	Location *time.Location
+	DayStart time.Duration
+}
+
+func (c Clock) location() *time.Location {
+	if c.Location == nil {
+		return time.Local
+	}
+	return c.Location
+}
+
+// day returns the report day containing t, formatted as 2006-01-02.
+func (c Clock) day(t time.Time) string {
+	return t.In(c.location()).Add(-c.DayStart).Format("2006-01-02")
+}
+
+// startOfDay returns the instant the report day containing t began.
+func (c Clock) startOfDay(t time.Time) time.Time {
+	return startOfDay(t.In(c.location()).Add(-c.DayStart)).Add(c.DayStart)
+}
+
+// date returns the instant report day 2006-01-02 begins.
+func (c Clock) date(s string) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02", s, c.location())
+	if err != nil {
+		return t, err
+	}
+	return t.Add(c.DayStart), nil
+}
+
+// ParseDayStart parses a -day-start value (HH:MM) into an offset from midnight.
+func ParseDayStart(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse %q (want HH:MM)", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// parseTimeBound parses a -since/-until value relative to now, using c for
+// day boundaries. It accepts dates (2006-01-02), date-times (2006-01-02T15:04 or
+// RFC 3339), "today", "yesterday", and relative forms: Nh (hours ago) or
+// Nd, Nw, Nm, Ny (calendar days, weeks, months, years before today). day
+// reports whether the value names a whole day rather than an instant.
+func parseTimeBound(s string, now time.Time, c Clock) (t time.Time, day bool, err error) {
+	s = strings.TrimSpace(s)
+	loc := c.location()
+	today := c.startOfDay(now)
+
+	switch strings.ToLower(s) {
+	case "today":
+		return today, true, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true, nil
+	}
+	if t, err := c.date(s); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04", s, loc); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+
+	if len(s) >= 2 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err == nil && n >= 0 {
+			switch s[len(s)-1] {
+			case 'h':
+				return now.Add(-time.Duration(n) * time.Hour), false, nil
+			case 'd':
+				return today.AddDate(0, 0, -n), true, nil
+			case 'w':
+				return today.AddDate(0, 0, -7*n), true, nil
+			case 'm':
+				return today.AddDate(0, -n, 0), true, nil
+			case 'y':
+				return today.AddDate(-n, 0, 0), true, nil
+			}
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("cannot parse %q (want YYYY-MM-DD, RFC 3339, today, yesterday or a relative form like 2w)", s)
+}
+
+// ParseSince returns the inclusive lower bound for a -since value.
+func ParseSince(s string, now time.Time, c Clock) (time.Time, error) {
+	t, _, err := parseTimeBound(s, now, c)
+	return t, err
+}
+
+// ParseUntil returns the exclusive upper bound for an -until value. Whole
+// days are inclusive, so "-until 2026-09-30" keeps all of September 30th.
+func ParseUntil(s string, now time.Time, c Clock) (time.Time, error) {
+	t, day, err := parseTimeBound(s, now, c)
+	if err != nil {
+		return t, err
+	}
+	if day {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
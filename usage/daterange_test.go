@@ -0,0 +1,107 @@
+package usage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeBound(t *testing.T) {
+	loc := time.FixedZone("test", 2*3600)
+	now := time.Date(2026, 3, 15, 13, 30, 0, 0, loc)
+	today := time.Date(2026, 3, 15, 0, 0, 0, 0, loc)
+
+	tests := []struct {
+		in   string
+		want time.Time
+		day  bool
+	}{
+		{"2026-09-01", time.Date(2026, 9, 1, 0, 0, 0, 0, loc), true},
+		{"2026-09-01T08:15", time.Date(2026, 9, 1, 8, 15, 0, 0, loc), false},
+		{"2026-09-01T08:15:00Z", time.Date(2026, 9, 1, 8, 15, 0, 0, time.UTC), false},
+		{"today", today, true},
+		{"Yesterday", today.AddDate(0, 0, -1), true},
+		{"12h", now.Add(-12 * time.Hour), false},
+		{"3d", today.AddDate(0, 0, -3), true},
+		{"2w", today.AddDate(0, 0, -14), true},
+		{"1m", time.Date(2026, 2, 15, 0, 0, 0, 0, loc), true},
+		{"1y", time.Date(2025, 3, 15, 0, 0, 0, 0, loc), true},
+	}
+	for _, tt := range tests {
+		got, day, err := parseTimeBound(tt.in, now, Clock{Location: loc})
+		if err != nil {
+			t.Errorf("parseTimeBound(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) || day != tt.day {
+			t.Errorf("parseTimeBound(%q) = %v, %v; want %v, %v", tt.in, got, day, tt.want, tt.day)
+		}
+	}
+
+	for _, bad := range []string{"", "w", "-2w", "2x", "2026/09/01", "last week"} {
+		if _, _, err := parseTimeBound(bad, now, Clock{Location: loc}); err == nil {
+			t.Errorf("parseTimeBound(%q): expected error", bad)
+		}
+	}
+}
+
+func TestParseUntil_WholeDaysInclusive(t *testing.T) {
+	now := time.Date(2026, 3, 15, 13, 30, 0, 0, time.UTC)
+
+	got, err := ParseUntil("2026-09-30", now, Clock{Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ParseUntil(date) = %v, want %v", got, want)
+	}
+
+	got, err = ParseUntil("2026-09-30T12:00", now, Clock{Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 9, 30, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ParseUntil(time) = %v, want %v", got, want)
+	}
+}
+
+func TestDayClock(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Date(2026, 2, 18, 17, 30, 0, 0, time.UTC) // 02:30 on the 19th in Tokyo
+
+	tests := []struct {
+		name  string
+		clock Clock
+		day   string
+		start time.Time
+	}{
+		{"utc", Clock{Location: time.UTC}, "2026-02-18", time.Date(2026, 2, 18, 0, 0, 0, 0, time.UTC)},
+		{"tokyo", Clock{Location: tokyo}, "2026-02-19", time.Date(2026, 2, 19, 0, 0, 0, 0, tokyo)},
+		{"tokyo 04:00", Clock{Location: tokyo, DayStart: 4 * time.Hour}, "2026-02-18", time.Date(2026, 2, 18, 4, 0, 0, 0, tokyo)},
+		{"utc 18:00", Clock{Location: time.UTC, DayStart: 18 * time.Hour}, "2026-02-17", time.Date(2026, 2, 17, 18, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := tt.clock.day(ts); got != tt.day {
+			t.Errorf("%s: day = %s, want %s", tt.name, got, tt.day)
+		}
+		if got := tt.clock.startOfDay(ts); !got.Equal(tt.start) {
+			t.Errorf("%s: startOfDay = %v, want %v", tt.name, got, tt.start)
+		}
+	}
+}
+
+func TestParseDayStart(t *testing.T) {
+	for in, want := range map[string]time.Duration{"00:00": 0, "04:00": 4 * time.Hour, "23:45": 23*time.Hour + 45*time.Minute} {
+		got, err := ParseDayStart(in)
+		if err != nil || got != want {
+			t.Errorf("ParseDayStart(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "4", "24:00", "04:60", "4am"} {
+		if _, err := ParseDayStart(bad); err == nil {
+			t.Errorf("ParseDayStart(%q): expected error", bad)
+		}
+	}
+}
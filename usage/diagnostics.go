@@ -0,0 +1,66 @@
+package usage
+
+import (
+	"sort"
+)
+
+// Kinds of Issue.
+const (
+	IssueMalformed        = "malformed_line"
+	IssueBadTimestamp     = "bad_timestamp"
+	IssueMissingTimestamp = "missing_timestamp"
+	IssueUnreadable       = "unreadable"
+)
+
+// Issue is a problem found while reading the logs. Line is 1-based, or 0
+// when the issue concerns the whole file or directory.
+type Issue struct {
+	Kind   string
+	Path   string
+	Line   int
+	Detail string
+}
+
+// Diagnostics collects everything that was skipped or guessed while
+// building a ParseResult. Issues cover every line of the files read, so
+// they are not limited to the report's date range: a malformed line has no
+// timestamp to filter by. Unmatched models only count requests in range.
+type Diagnostics struct {
+	Issues []Issue // ordered by path and line
+	// UnknownDates counts records without a usable timestamp, which the
+	// daily breakdown files under "unknown".
+	UnknownDates int
+	// UnmatchedModels holds usage of models ResolvePricing has no entry or
+	// family prefix for, priced with defaultPricing.
+	UnmatchedModels map[string]*Bucket
+}
+
+// Count is the number of distinct problems: one per issue, plus one per
+// unmatched model.
+func (d *Diagnostics) Count() int {
+	return len(d.Issues) + len(d.UnmatchedModels)
+}
+
+// IssuesOf returns the issues of one kind.
+func (d *Diagnostics) IssuesOf(kind string) []Issue {
+	var issues []Issue
+	for _, is := range d.Issues {
+		if is.Kind == kind {
+			issues = append(issues, is)
+		}
+	}
+	return issues
+}
+
+func addIssue(state *fileState, kind, detail string) {
+	state.Issues = append(state.Issues, Issue{Kind: kind, Line: state.Lines, Detail: detail})
+}
+
+func sortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Path != issues[j].Path {
+			return issues[i].Path < issues[j].Path
+		}
+		return issues[i].Line < issues[j].Line
+	})
+}
@@ -0,0 +1,101 @@
+package usage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	lines := []string{
+		makeRecord("req_ok", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		`{"type":"assistant","requestId":"req_broken",`,
+		`{"type":"user","message":{"role":"user","content":"hi"}}`,
+		makeRecord("req_badts", "claude-opus-4-6", "not-a-time", 100, 50, 0, 0, 0),
+		`{"type":"assistant","requestId":"req_nots","message":{"model":"claude-opus-4-6","role":"assistant","usage":{"input_tokens":100,"output_tokens":50}}}`,
+		makeRecord("req_gpt", "gpt-5", ts(0, 11), 100, 50, 0, 0, 0),
+		makeRecord("req_gpt2", "gpt-5", ts(0, 12), 100, 50, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+	path, _ := filepath.Abs(filepath.Join(base, "projects", "test-project", "session.jsonl"))
+
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := data.Diagnostics
+
+	want := []Issue{
+		{Kind: IssueMalformed, Path: path, Line: 2},
+		{Kind: IssueBadTimestamp, Path: path, Line: 4, Detail: "not-a-time"},
+		{Kind: IssueMissingTimestamp, Path: path, Line: 5},
+	}
+	if len(d.Issues) != len(want) {
+		t.Fatalf("got %d issues, want %d: %+v", len(d.Issues), len(want), d.Issues)
+	}
+	for i, w := range want {
+		got := d.Issues[i]
+		if got.Kind != w.Kind || got.Path != w.Path || got.Line != w.Line {
+			t.Errorf("issue %d = %+v, want kind %s at %s:%d", i, got, w.Kind, w.Path, w.Line)
+		}
+		if w.Detail != "" && got.Detail != w.Detail {
+			t.Errorf("issue %d detail = %q, want %q", i, got.Detail, w.Detail)
+		}
+	}
+	if d.Issues[0].Detail == "" {
+		t.Error("malformed line issue should carry the JSON error")
+	}
+
+	assertInt(t, "UnknownDates", d.UnknownDates, 2)
+	if b := d.UnmatchedModels["gpt-5"]; b == nil || b.Requests != 2 {
+		t.Errorf("UnmatchedModels[gpt-5] = %+v, want 2 requests", b)
+	}
+	if _, ok := d.UnmatchedModels["claude-opus-4-6"]; ok {
+		t.Error("known model listed as unmatched")
+	}
+	assertInt(t, "Count", d.Count(), 4)
+}
+
+func TestDiagnostics_CleanFixture(t *testing.T) {
+	data, err := parseLogs(Options{BaseDir: "testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := data.Diagnostics.Count(); n != 0 {
+		t.Errorf("fixture has %d diagnostics, want 0: %+v", n, data.Diagnostics.Issues)
+	}
+}
+
+func TestDiagnostics_SurviveCacheAndResume(t *testing.T) {
+	base := setupProject(t, "test-project", []string{
+		`{"type":"assistant","requestId":"req_broken",`,
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	path := filepath.Join(base, "projects", "test-project", "session.jsonl")
+	opts := Options{BaseDir: base, CacheDir: t.TempDir()}
+
+	if _, err := parseLogs(opts); err != nil {
+		t.Fatal(err)
+	}
+	// A partial trailing line is reported, since a crash may have cut it
+	// off for good, and once it is complete it is still reported once,
+	// under its own line number.
+	appendLines(t, path, `{"type":"assistant","requestId":"req_2",`)
+	bumpModTime(t, path)
+	data, err := parseLogs(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "issues with partial line", len(data.Diagnostics.Issues), 2)
+	assertInt(t, "ParseErrors with partial line", data.ParseErrors, 2)
+
+	appendLines(t, path, " oops\n")
+	bumpModTime(t, path)
+	data, err = parseLogs(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := data.Diagnostics.Issues
+	if len(issues) != 2 || issues[0].Line != 1 || issues[1].Line != 3 {
+		t.Errorf("issues after resume = %+v, want malformed lines 1 and 3", issues)
+	}
+}
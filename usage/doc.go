@@ -0,0 +1,15 @@
+// Package usage parses Claude Code session logs and aggregates their token
+// usage and estimated cost.
+//
+// Claude Code writes one JSONL transcript per session under
+// ~/.claude/projects/<project>/, with subagent transcripts in a subagents/
+// directory next to it. A Parser reads those logs, deduplicates streamed
+// entries by request id, prices each request with the built-in pricing
+// table and returns a ParseResult broken down by model, day, project,
+// session and more:
+//
+//	data, err := usage.NewParser(usage.Options{BaseDir: home + "/.claude"}).Parse()
+//
+// ParseFS and ParseReader read logs from an fs.FS or a single io.Reader
+// instead, and a Watcher follows logs as they are written.
+package usage
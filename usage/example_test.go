@@ -0,0 +1,43 @@
+package usage_test
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"testing/fstest"
+
+	"github.com/backstabslash/goccc/usage"
+)
+
+const exampleLog = `{"type":"assistant","requestId":"req_1","sessionId":"s1","cwd":"/home/me/app","timestamp":"2026-03-02T10:00:00Z","message":{"model":"claude-sonnet-4-5-20250929","usage":{"input_tokens":1000,"output_tokens":500}}}
+{"type":"assistant","requestId":"req_2","sessionId":"s1","cwd":"/home/me/app","timestamp":"2026-03-02T10:01:00Z","message":{"model":"claude-sonnet-4-5-20250929","usage":{"input_tokens":2000,"output_tokens":1000}}}
+`
+
+func ExampleParser_ParseFS() {
+	fsys := fstest.MapFS{
+		"-home-me-app/s1.jsonl": {Data: []byte(exampleLog)},
+	}
+	data, err := usage.NewParser(usage.Options{}).ParseFS(fsys)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for model, b := range data.ModelUsage {
+		fmt.Printf("%s: %d requests, $%.4f\n", usage.ModelName(model), b.Requests, b.Cost)
+	}
+	// Output: Sonnet 4.5: 2 requests, $0.0315
+}
+
+func ExampleParser_ParseReader() {
+	data, err := usage.NewParser(usage.Options{}).ParseReader(strings.NewReader(exampleLog))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(data.ProjectPaths["-home-me-app"], data.TotalRecords)
+	// Output: /home/me/app 2
+}
+
+func ExampleCalcCost() {
+	cost := usage.CalcCost("claude-opus-4-6", usage.Usage{InputTokens: 1_000_000})
+	fmt.Printf("$%.2f\n", cost)
+	// Output: $5.00
+}
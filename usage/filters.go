@@ -0,0 +1,101 @@
+package usage
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// recordFilter holds the project and model filters, which are applied once
+// the real project paths are known. All of them must pass.
+type recordFilter struct {
+	project       string
+	projectFilter projectFilter
+	projectRe     *regexp.Regexp
+	exclude       string
+	excludeFilter projectFilter
+	excludeRe     *regexp.Regexp
+	model         string // lowercased
+	modelRe       *regexp.Regexp
+}
+
+func newRecordFilter(opts Options) recordFilter {
+	return recordFilter{
+		project:       opts.ProjectFilter,
+		projectFilter: newProjectFilter(opts.ProjectFilter),
+		projectRe:     opts.ProjectRegex,
+		exclude:       opts.ExcludeProject,
+		excludeFilter: newProjectFilter(opts.ExcludeProject),
+		excludeRe:     opts.ExcludeProjectRegex,
+		model:         strings.ToLower(opts.ModelFilter),
+		modelRe:       opts.ModelRegex,
+	}
+}
+
+func (f recordFilter) active() bool {
+	return f.project != "" || f.projectRe != nil || f.exclude != "" || f.excludeRe != nil ||
+		f.model != "" || f.modelRe != nil
+}
+
+// projectPath is what project regexes match: the real path with forward
+// slashes when it is known, the slug otherwise.
+func projectPath(slug, cwd string) string {
+	if cwd == "" {
+		return slug
+	}
+	return strings.ReplaceAll(cwd, `\`, "/")
+}
+
+// keepProject reports whether a project passes the project filters.
+func (f recordFilter) keepProject(slug, cwd string) bool {
+	if f.project != "" && !f.projectFilter.matches(slug, cwd) {
+		return false
+	}
+	if f.projectRe != nil && !f.projectRe.MatchString(projectPath(slug, cwd)) {
+		return false
+	}
+	if f.exclude != "" && f.excludeFilter.matches(slug, cwd) {
+		return false
+	}
+	if f.excludeRe != nil && f.excludeRe.MatchString(projectPath(slug, cwd)) {
+		return false
+	}
+	return true
+}
+
+// keepModel reports whether a model passes the model filters, which match
+// either its raw id (claude-opus-4-6) or its display name (Opus 4.6).
+func (f recordFilter) keepModel(model string) bool {
+	short := ModelName(model)
+	if f.model != "" && !strings.Contains(strings.ToLower(model), f.model) && !strings.Contains(strings.ToLower(short), f.model) {
+		return false
+	}
+	if f.modelRe != nil && !f.modelRe.MatchString(model) && !f.modelRe.MatchString(short) {
+		return false
+	}
+	return true
+}
+
+// describeFilters lists the active filters for the report header.
+func describeFilters(opts Options) []string {
+	var filters []string
+	add := func(name, value string) {
+		if value != "" {
+			filters = append(filters, fmt.Sprintf("%s %s", name, value))
+		}
+	}
+	re := func(r *regexp.Regexp) string {
+		if r == nil {
+			return ""
+		}
+		return "/" + r.String() + "/"
+	}
+	add("project", opts.ProjectFilter)
+	add("project", re(opts.ProjectRegex))
+	add("not project", opts.ExcludeProject)
+	add("not project", re(opts.ExcludeProjectRegex))
+	add("model", opts.ModelFilter)
+	add("model", re(opts.ModelRegex))
+	add("branch", opts.BranchFilter)
+	return filters
+}
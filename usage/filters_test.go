@@ -0,0 +1,90 @@
+package usage
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestRecordFilter_Model(t *testing.T) {
+	tests := []struct {
+		opts  Options
+		model string
+		want  bool
+	}{
+		{Options{ModelFilter: "opus"}, "claude-opus-4-6", true},
+		{Options{ModelFilter: "OPUS"}, "claude-opus-4-6", true},
+		{Options{ModelFilter: "Opus 4.6"}, "claude-opus-4-6", true},
+		{Options{ModelFilter: "opus-4-6"}, "claude-opus-4-6-20260101", true},
+		{Options{ModelFilter: "Opus 4.6"}, "claude-opus-4-5", false},
+		{Options{ModelFilter: "sonnet"}, "claude-haiku-4-5", false},
+		{Options{ModelRegex: regexp.MustCompile(`^(Opus|Sonnet) 4\.6$`)}, "claude-sonnet-4-6", true},
+		{Options{ModelRegex: regexp.MustCompile(`^claude-haiku`)}, "claude-haiku-4-5", true},
+		{Options{ModelRegex: regexp.MustCompile(`^claude-haiku`)}, "claude-opus-4-6", false},
+		{Options{ModelFilter: "opus", ModelRegex: regexp.MustCompile(`4\.5`)}, "claude-opus-4-6", false},
+	}
+	for _, tt := range tests {
+		if got := newRecordFilter(tt.opts).keepModel(tt.model); got != tt.want {
+			t.Errorf("keepModel(%q) with %+v = %v, want %v", tt.model, tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestRecordFilter_Project(t *testing.T) {
+	const slug, cwd = "-home-alice-git-webapp", "/home/alice/git/webapp"
+	tests := []struct {
+		opts Options
+		cwd  string
+		want bool
+	}{
+		{Options{ExcludeProject: "webapp"}, cwd, false},
+		{Options{ExcludeProject: "api"}, cwd, true},
+		{Options{ProjectFilter: "git", ExcludeProject: "webapp"}, cwd, false},
+		{Options{ProjectRegex: regexp.MustCompile(`/git/[^/]+$`)}, cwd, true},
+		{Options{ProjectRegex: regexp.MustCompile(`^/srv/`)}, cwd, false},
+		{Options{ExcludeProjectRegex: regexp.MustCompile(`webapp$`)}, cwd, false},
+		// Without a cwd, regexes see the slug.
+		{Options{ProjectRegex: regexp.MustCompile(`-git-webapp$`)}, "", true},
+		{Options{ProjectRegex: regexp.MustCompile(`/git/webapp$`)}, "", false},
+	}
+	for _, tt := range tests {
+		if got := newRecordFilter(tt.opts).keepProject(slug, tt.cwd); got != tt.want {
+			t.Errorf("keepProject(%q) with %+v = %v, want %v", tt.cwd, tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestFilters_Compose(t *testing.T) {
+	base := setupProject(t, "-home-alice-webapp", []string{
+		withCwd(makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0), "/home/alice/webapp"),
+		withCwd(makeRecord("req_2", "claude-haiku-4-5", ts(0, 11), 100, 50, 0, 0, 0), "/home/alice/webapp"),
+		withCwd(makeLimitRecord("req_limit", ts(0, 12), "Claude AI usage limit reached|1755295200"), "/home/alice/webapp"),
+	})
+	addProject(t, base, "-home-alice-scratch", []string{
+		withCwd(makeRecord("req_3", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0), "/home/alice/scratch"),
+	})
+
+	opts := Options{BaseDir: base, ModelFilter: "opus", ExcludeProject: "scratch"}
+	data, err := parseLogs(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalRecords", data.TotalRecords, 1)
+	if data.ModelUsage["claude-opus-4-6"] == nil {
+		t.Error("opus record from webapp missing")
+	}
+	// Limit hits have no model; a model filter must not drop them.
+	assertInt(t, "Limits", len(data.Limits), 1)
+	want := []string{"not project scratch", "model opus"}
+	if !reflect.DeepEqual(data.Filters, want) {
+		t.Errorf("Filters = %q, want %q", data.Filters, want)
+	}
+
+	opts.ExcludeProject = "webapp"
+	data, err = parseLogs(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalRecords excluding webapp", data.TotalRecords, 1)
+	assertInt(t, "Limits excluding webapp", len(data.Limits), 0)
+}
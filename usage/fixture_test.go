@@ -0,0 +1,357 @@
+package usage
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestFixture_RealisticConversation runs parseLogs against the static testdata/
+// fixture — a multi-turn JWT refactor conversation with streaming duplicates,
+// subagent files, thinking blocks, mixed cache tiers, a <synthetic> error entry,
+// and two different models (Opus main + Haiku subagent).
+//
+// All expected values are hand-calculated from the fixture JSONL files.
+func TestFixture_RealisticConversation(t *testing.T) {
+	data, err := parseLogs(Options{BaseDir: "testdata"})
+	if err != nil {
+		t.Fatalf("parseLogs: %v", err)
+	}
+
+	// --- File and record counts ---
+
+	if data.TotalFiles != 2 {
+		t.Errorf("TotalFiles = %d, want 2 (main + subagent)", data.TotalFiles)
+	}
+
+	// 7 unique requestIds after dedup:
+	//   main:     req_main_001, req_main_002, req_main_003, req_main_004
+	//   subagent: req_sub_001,  req_sub_002,  req_sub_003
+	// <synthetic> req_error_001 is filtered out.
+	if data.TotalRecords != 7 {
+		t.Errorf("TotalRecords = %d, want 7", data.TotalRecords)
+	}
+
+	if data.ParseErrors != 0 {
+		t.Errorf("ParseErrors = %d, want 0", data.ParseErrors)
+	}
+
+	// --- Opus 4.6 model bucket ---
+	// Final values after dedup (last-write-wins per requestId):
+	//   req_main_001: in=15000  out=2500  cr=50000  cw5m=8000  cw1h=3000
+	//   req_main_002: in=22000  out=4800  cr=62000  cw5m=0     cw1h=5000
+	//   req_main_003: in=30000  out=6200  cr=70000  cw5m=2000  cw1h=0
+	//   req_main_004: in=35000  out=3500  cr=80000  cw5m=4000  cw1h=0     (flat fallback)
+
+	opus := data.ModelUsage["claude-opus-4-6"]
+	if opus == nil {
+		t.Fatal("missing Opus 4.6 model bucket")
+	}
+	assertInt(t, "opus.Requests", opus.Requests, 4)
+	assertInt(t, "opus.InputTokens", opus.InputTokens, 102000)
+	assertInt(t, "opus.OutputTokens", opus.OutputTokens, 17000)
+	assertInt(t, "opus.CacheRead", opus.CacheRead, 262000)
+	assertInt(t, "opus.CacheWrite5m", opus.CacheWrite5m, 14000)
+	assertInt(t, "opus.CacheWrite1h", opus.CacheWrite1h, 8000)
+
+	// Opus cost per request (pricing: Input=$5/M, Output=$25/M,
+	//   CacheWrite5m=$6.25/M, CacheWrite1h=$10/M, CacheRead=$0.50/M):
+	//   req_main_001: 0.075 + 0.0625  + 0.05   + 0.03  + 0.025  = 0.2425
+	//   req_main_002: 0.11  + 0.12    + 0      + 0.05  + 0.031  = 0.311
+	//   req_main_003: 0.15  + 0.155   + 0.0125 + 0     + 0.035  = 0.3525
+	//   req_main_004: 0.175 + 0.0875  + 0.025  + 0     + 0.04   = 0.3275
+	//   Total: 1.2335
+	assertCost(t, "opus.Cost", opus.Cost, 1.2335)
+
+	// --- Haiku 4.5 model bucket ---
+	// Final values after dedup:
+	//   req_sub_001: in=5000  out=1200  cr=12000  cw5m=3000  cw1h=0
+	//   req_sub_002: in=8000  out=2800  cr=15000  cw5m=0     cw1h=2000
+	//   req_sub_003: in=6000  out=900   cr=18000  cw5m=1500  cw1h=0
+
+	haiku := data.ModelUsage["claude-haiku-4-5-20251001"]
+	if haiku == nil {
+		t.Fatal("missing Haiku 4.5 model bucket")
+	}
+	assertInt(t, "haiku.Requests", haiku.Requests, 3)
+	assertInt(t, "haiku.InputTokens", haiku.InputTokens, 19000)
+	assertInt(t, "haiku.OutputTokens", haiku.OutputTokens, 4900)
+	assertInt(t, "haiku.CacheRead", haiku.CacheRead, 45000)
+	assertInt(t, "haiku.CacheWrite5m", haiku.CacheWrite5m, 4500)
+	assertInt(t, "haiku.CacheWrite1h", haiku.CacheWrite1h, 2000)
+
+	// Haiku cost per request (pricing: Input=$1/M, Output=$5/M,
+	//   CacheWrite5m=$1.25/M, CacheWrite1h=$2/M, CacheRead=$0.10/M):
+	//   req_sub_001: 0.005  + 0.006  + 0.00375 + 0     + 0.0012 = 0.01595
+	//   req_sub_002: 0.008  + 0.014  + 0       + 0.004 + 0.0015 = 0.0275
+	//   req_sub_003: 0.006  + 0.0045 + 0.001875+ 0     + 0.0018 = 0.014175
+	//   Total: 0.057625
+	assertCost(t, "haiku.Cost", haiku.Cost, 0.057625)
+
+	// --- No other models should exist ---
+	if len(data.ModelUsage) != 2 {
+		t.Errorf("len(ModelUsage) = %d, want 2 (opus + haiku)", len(data.ModelUsage))
+	}
+
+	// --- Daily aggregation ---
+
+	// 2026-02-18: req_main_001 + req_main_002 (both Opus)
+	day18 := data.DailyUsage["2026-02-18"]
+	if day18 == nil {
+		t.Fatal("missing daily bucket for 2026-02-18")
+	}
+	if day18opus := day18["claude-opus-4-6"]; day18opus != nil {
+		assertInt(t, "day18.opus.Requests", day18opus.Requests, 2)
+		assertInt(t, "day18.opus.InputTokens", day18opus.InputTokens, 37000)
+		assertCost(t, "day18.opus.Cost", day18opus.Cost, 0.5535)
+	} else {
+		t.Error("missing opus bucket for 2026-02-18")
+	}
+
+	// 2026-02-19: req_main_003 + req_main_004 (Opus) + all 3 subagent reqs (Haiku)
+	day19 := data.DailyUsage["2026-02-19"]
+	if day19 == nil {
+		t.Fatal("missing daily bucket for 2026-02-19")
+	}
+	if day19opus := day19["claude-opus-4-6"]; day19opus != nil {
+		assertInt(t, "day19.opus.Requests", day19opus.Requests, 2)
+		assertCost(t, "day19.opus.Cost", day19opus.Cost, 0.68)
+	} else {
+		t.Error("missing opus bucket for 2026-02-19")
+	}
+	if day19haiku := day19["claude-haiku-4-5-20251001"]; day19haiku != nil {
+		assertInt(t, "day19.haiku.Requests", day19haiku.Requests, 3)
+		assertCost(t, "day19.haiku.Cost", day19haiku.Cost, 0.057625)
+	} else {
+		t.Error("missing haiku bucket for 2026-02-19")
+	}
+
+	// --- Project aggregation ---
+
+	proj := data.ProjectUsage["C--Users-alice-git-webapp"]
+	if proj == nil {
+		t.Fatal("missing project bucket")
+	}
+	if len(proj) != 2 {
+		t.Errorf("project has %d models, want 2", len(proj))
+	}
+	assertCost(t, "project.opus.Cost", proj["claude-opus-4-6"].Cost, 1.2335)
+	assertCost(t, "project.haiku.Cost", proj["claude-haiku-4-5-20251001"].Cost, 0.057625)
+}
+
+func TestFixture_SyntheticEntriesSkipped(t *testing.T) {
+	data, err := parseLogs(Options{BaseDir: "testdata"})
+	if err != nil {
+		t.Fatalf("parseLogs: %v", err)
+	}
+	for model := range data.ModelUsage {
+		if model == "<synthetic>" {
+			t.Error("<synthetic> model should be filtered out")
+		}
+	}
+}
+
+func TestFixture_LimitHits(t *testing.T) {
+	data, err := parseLogs(Options{BaseDir: "testdata"})
+	if err != nil {
+		t.Fatalf("parseLogs: %v", err)
+	}
+	if len(data.Limits) != 1 {
+		t.Fatalf("got %d limit hits, want 1", len(data.Limits))
+	}
+	h := data.Limits[0]
+	if h.Project != "C--Users-alice-git-webapp" || h.Session != "abc123" {
+		t.Errorf("limit hit project/session = %q/%q", h.Project, h.Session)
+	}
+	if want := time.Date(2026, 2, 19, 10, 5, 0, 0, time.UTC); !h.Timestamp.Equal(want) {
+		t.Errorf("limit hit Timestamp = %v, want %v", h.Timestamp, want)
+	}
+	if want := time.Unix(1755295200, 0); !h.ResetAt.Equal(want) {
+		t.Errorf("limit hit ResetAt = %v, want %v", h.ResetAt, want)
+	}
+}
+
+func TestFixture_ProjectPaths(t *testing.T) {
+	data, err := parseLogs(Options{BaseDir: "testdata"})
+	if err != nil {
+		t.Fatalf("parseLogs: %v", err)
+	}
+	if got := data.ProjectPaths["C--Users-alice-git-webapp"]; got != `C:\Users\alice\git\webapp` {
+		t.Errorf("ProjectPaths = %q, want the recorded cwd", got)
+	}
+
+	// Filters match the real path, with either separator.
+	for _, filter := range []string{`git\webapp`, "git/webapp"} {
+		data, err = parseLogs(Options{BaseDir: "testdata", ProjectFilter: filter})
+		if err != nil {
+			t.Fatalf("parseLogs: %v", err)
+		}
+		if data.TotalRecords != 7 {
+			t.Errorf("filter %q: TotalRecords = %d, want 7", filter, data.TotalRecords)
+		}
+	}
+}
+
+func TestFixture_ProjectFilter(t *testing.T) {
+	data, err := parseLogs(Options{BaseDir: "testdata", ProjectFilter: "alice"})
+	if err != nil {
+		t.Fatalf("parseLogs: %v", err)
+	}
+	if data.TotalRecords != 7 {
+		t.Errorf("filter 'alice': TotalRecords = %d, want 7", data.TotalRecords)
+	}
+
+	data, err = parseLogs(Options{BaseDir: "testdata", ProjectFilter: "nonexistent"})
+	if err != nil {
+		t.Fatalf("parseLogs: %v", err)
+	}
+	if data.TotalRecords != 0 {
+		t.Errorf("filter 'nonexistent': TotalRecords = %d, want 0", data.TotalRecords)
+	}
+}
+
+func TestFixture_SessionRollup(t *testing.T) {
+	data, err := parseLogs(Options{BaseDir: "testdata"})
+	if err != nil {
+		t.Fatalf("parseLogs: %v", err)
+	}
+	if len(data.SessionUsage) != 1 {
+		t.Fatalf("len(SessionUsage) = %d, want 1 (subagent rolls into parent)", len(data.SessionUsage))
+	}
+	s := data.SessionUsage["abc123"]
+	if s == nil {
+		t.Fatal("missing session abc123")
+	}
+	if s.Project != "C--Users-alice-git-webapp" {
+		t.Errorf("session project = %q", s.Project)
+	}
+	reqs, cost := s.Totals()
+	assertInt(t, "session.Requests", reqs, 7)
+	assertCost(t, "session.Cost", cost, 1.291125)
+	if len(s.Models) != 2 {
+		t.Errorf("session has %d models, want 2", len(s.Models))
+	}
+	// First/last come from the final streaming entry of each request.
+	if got := s.First.UTC().Format(time.RFC3339); got != "2026-02-18T09:00:12Z" {
+		t.Errorf("session.First = %s", got)
+	}
+	if got := s.Last.UTC().Format(time.RFC3339); got != "2026-02-19T11:00:10Z" {
+		t.Errorf("session.Last = %s", got)
+	}
+}
+
+func TestFixture_ThreadSplit(t *testing.T) {
+	data, err := parseLogs(Options{BaseDir: "testdata"})
+	if err != nil {
+		t.Fatalf("parseLogs: %v", err)
+	}
+	// Main transcript is all Opus; the subagent transcript is all Haiku.
+	mainThread, subagents := data.ThreadTotals()
+	assertInt(t, "main.Requests", mainThread.Requests, 4)
+	assertCost(t, "main.Cost", mainThread.Cost, 1.2335)
+	assertInt(t, "subagents.Requests", subagents.Requests, 3)
+	assertCost(t, "subagents.Cost", subagents.Cost, 0.057625)
+
+	// The fixture has no Task call linking agent a1b2c3d to a type.
+	if b := data.AgentTypeUsage[""]["claude-haiku-4-5-20251001"]; b == nil || b.Requests != 3 {
+		t.Errorf("unknown agent type bucket = %+v, want 3 requests", b)
+	}
+}
+
+func TestFixture_ToolUsage(t *testing.T) {
+	data, err := parseLogs(Options{BaseDir: "testdata", Tools: true})
+	if err != nil {
+		t.Fatalf("parseLogs: %v", err)
+	}
+	// Read, Grep, Write and Edit in the main transcript, one call each;
+	// Grep twice in the subagent, whose req_sub_002 calls no tool.
+	// req_main_001's Read only appears in its last streaming entry.
+	assertInt(t, "Read.Calls", data.ToolUsage["Read"].Calls, 1)
+	assertCost(t, "Read.Cost", data.ToolUsage["Read"].Cost, 0.2425)
+	assertInt(t, "Grep.Calls", data.ToolUsage["Grep"].Calls, 3)
+	assertCost(t, "Grep.Cost", data.ToolUsage["Grep"].Cost, 0.341125)
+	assertInt(t, "no-tools.Requests", data.ToolUsage[""].Requests, 1)
+	assertCost(t, "no-tools.Cost", data.ToolUsage[""].Cost, 0.0275)
+	if len(data.MCPServerUsage) != 0 {
+		t.Errorf("MCPServerUsage = %v, want empty", data.MCPServerUsage)
+	}
+}
+
+func assertInt(t *testing.T, name string, got, want int) {
+	t.Helper()
+	if got != want {
+		t.Errorf("%s = %d, want %d", name, got, want)
+	}
+}
+
+func assertCost(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 0.000001 {
+		t.Errorf("%s = %.6f, want %.6f", name, got, want)
+	}
+}
+
+func TestFixture_ParseFS(t *testing.T) {
+	want, err := parseLogs(Options{BaseDir: "testdata"})
+	if err != nil {
+		t.Fatalf("parseLogs: %v", err)
+	}
+	data, err := NewParser(Options{ModelFilter: "opus"}).ParseFS(os.DirFS("testdata/projects"))
+	if err != nil {
+		t.Fatalf("ParseFS: %v", err)
+	}
+	assertInt(t, "TotalFiles", data.TotalFiles, 2)
+	assertInt(t, "TotalRecords", data.TotalRecords, 4)
+	assertCost(t, "opus cost", data.ModelUsage["claude-opus-4-6"].Cost, want.ModelUsage["claude-opus-4-6"].Cost)
+	if s := data.SessionUsage["abc123"]; s == nil || s.Project != "C--Users-alice-git-webapp" {
+		t.Errorf("SessionUsage[abc123] = %+v, want project C--Users-alice-git-webapp", s)
+	}
+}
+
+func TestFixture_ParseReader(t *testing.T) {
+	f, err := os.Open("testdata/projects/C--Users-alice-git-webapp/abc123.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data, err := NewParser(Options{}).ParseReader(f)
+	if err != nil {
+		t.Fatalf("ParseReader: %v", err)
+	}
+	assertInt(t, "TotalRecords", data.TotalRecords, 4)
+	if _, ok := data.ProjectUsage["C--Users-alice-git-webapp"]; !ok {
+		t.Errorf("ProjectUsage = %v, want the project of the recorded cwd", data.ProjectUsage)
+	}
+	if _, ok := data.SessionUsage["abc123"]; !ok {
+		t.Error("want the session id recorded in the log")
+	}
+}
+
+func TestFixture_Records(t *testing.T) {
+	data, err := parseLogs(Options{BaseDir: "testdata"})
+	if err != nil {
+		t.Fatalf("parseLogs: %v", err)
+	}
+	if data.Records != nil {
+		t.Errorf("Records = %d rows, want none without Options.Records", len(data.Records))
+	}
+
+	data, err = parseLogs(Options{BaseDir: "testdata", Records: true, ModelFilter: "haiku"})
+	if err != nil {
+		t.Fatalf("parseLogs: %v", err)
+	}
+	assertInt(t, "len(Records)", len(data.Records), 3)
+	var cost float64
+	for i, r := range data.Records {
+		if want := fmt.Sprintf("req_sub_%03d", i+1); r.RequestID != want {
+			t.Errorf("Records[%d].RequestID = %q, want %q", i, r.RequestID, want)
+		}
+		if i > 0 && r.Timestamp.Before(data.Records[i-1].Timestamp) {
+			t.Errorf("Records[%d] is out of order", i)
+		}
+		cost += r.Bucket().Cost
+	}
+	assertCost(t, "records cost", cost, data.ModelUsage["claude-haiku-4-5-20251001"].Cost)
+}
@@ -0,0 +1,103 @@
+package usage
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stdinPath stands for standard input in Options.Files.
+const stdinPath = "-"
+
+// readerName stands in for the path of a log read with Parser.ParseReader.
+const readerName = "reader"
+
+// inputFiles expands Options.Files into the files to read, in the order
+// given. A pattern that matches nothing is an error, since it is most likely
+// a typo rather than an empty result.
+func inputFiles(patterns []string) ([]logFile, error) {
+	var files []logFile
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		if pattern == stdinPath {
+			if !seen[stdinPath] {
+				seen[stdinPath] = true
+				files = append(files, logFile{fileSource: fileSource{Session: "stdin"}, path: stdinPath, order: len(files)})
+			}
+			continue
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file pattern %q: %v", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", pattern)
+		}
+		for _, m := range matches {
+			path, err := filepath.Abs(m)
+			if err != nil {
+				return nil, err
+			}
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if info.IsDir() {
+				return nil, fmt.Errorf("%s is a directory (use -base-dir for a Claude Code data directory)", m)
+			}
+			// The path still tells a subagent transcript from a session, but
+			// the project is taken from the records; see inputProject.
+			src := sourceForPath(path)
+			src.Project = ""
+			files = append(files, logFile{fileSource: src, path: path, order: len(files), size: info.Size(), modTime: info.ModTime()})
+		}
+	}
+	return files, nil
+}
+
+// inputProject names the project of a session read from a log with no
+// project directory, with -f or ParseReader: the slug of its records'
+// working directory, so it lines up with the same project read from
+// ~/.claude. Each session is named on its own, since a piped log may hold
+// the sessions of several projects.
+func inputProject(cwd string) string {
+	if cwd == "" {
+		return "unknown"
+	}
+	return projectSlug(cwd)
+}
+
+// fsLogs lists the logs in fsys for Parser.ParseFS, in walk order.
+func fsLogs(fsys fs.FS) ([]logFile, error) {
+	var files []logFile
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if _, ok := logBaseName(d.Name()); !ok {
+			return nil
+		}
+		src := sourceForPath(name)
+		if !strings.Contains(name, "/") {
+			src.Project = ""
+		}
+		files = append(files, logFile{
+			fileSource: src,
+			path:       name,
+			order:      len(files),
+			open:       func() (io.ReadCloser, error) { return openFSLog(fsys, name) },
+		})
+		return nil
+	})
+	return files, err
+}
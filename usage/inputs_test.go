@@ -0,0 +1,175 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTranscript(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := ""
+	for _, l := range lines {
+		content += l + "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func withCwd(record, cwd string) string {
+	return record[:len(record)-1] + `,"cwd":"` + cwd + `"}`
+}
+
+func TestInputFiles_ProjectFromCwd(t *testing.T) {
+	dir := t.TempDir()
+	writeTranscript(t, filepath.Join(dir, "a.jsonl"),
+		withCwd(makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0), "/home/alice/git/webapp"),
+		withCwd(makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 100, 50, 0, 0, 0), "/home/alice/git/webapp/src"))
+	writeTranscript(t, filepath.Join(dir, "b.jsonl"),
+		makeRecord("req_3", "claude-opus-4-6", ts(0, 12), 100, 50, 0, 0, 0))
+
+	data, err := parseLogs(Options{Files: []string{filepath.Join(dir, "*.jsonl")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalFiles", data.TotalFiles, 2)
+	assertInt(t, "TotalRecords", data.TotalRecords, 3)
+	slug := projectSlug("/home/alice/git/webapp")
+	if b := data.ProjectUsage[slug]["claude-opus-4-6"]; b == nil || b.Requests != 2 {
+		t.Errorf("project %s = %+v, want 2 requests", slug, b)
+	}
+	if got := data.ProjectPaths[slug]; got != "/home/alice/git/webapp" {
+		t.Errorf("ProjectPaths[%s] = %q", slug, got)
+	}
+	if data.ProjectUsage["unknown"] == nil {
+		t.Error("records without a cwd should fall under project \"unknown\"")
+	}
+
+	data, err = parseLogs(Options{Files: []string{filepath.Join(dir, "*.jsonl")}, ProjectFilter: "webapp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalRecords with -project", data.TotalRecords, 2)
+}
+
+func TestInputFiles_DedupAndSubagents(t *testing.T) {
+	dir := t.TempDir()
+	rec := makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0)
+	main := filepath.Join(dir, "sess", "sess.jsonl")
+	sub := filepath.Join(dir, "sess", "sess", "subagents", "agent-a1.jsonl")
+	writeTranscript(t, main, rec)
+	writeTranscript(t, sub, makeRecord("req_sub", "claude-haiku-4-5", ts(0, 10), 10, 5, 0, 0, 0))
+
+	// The same file twice, and the same record in two files, count once.
+	data, err := parseLogs(Options{Files: []string{main, main, sub}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalFiles", data.TotalFiles, 2)
+	assertInt(t, "TotalRecords", data.TotalRecords, 2)
+	_, subagents := data.ThreadTotals()
+	assertInt(t, "subagent requests", subagents.Requests, 1)
+}
+
+func TestInputFiles_Errors(t *testing.T) {
+	dir := t.TempDir()
+	for _, files := range [][]string{
+		{filepath.Join(dir, "missing.jsonl")},
+		{filepath.Join(dir, "*.jsonl")},
+		{dir},
+		{"[bad"},
+	} {
+		if _, err := parseLogs(Options{Files: files}); err == nil {
+			t.Errorf("parseLogs(Files: %q) should fail", files)
+		}
+	}
+}
+
+func TestInputFiles_Stdin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "in.jsonl")
+	writeTranscript(t, path,
+		withCwd(makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0), "/srv/api"),
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 80, 0, 0, 0))
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+
+	data, err := parseLogs(Options{Files: []string{"-"}, CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalRecords", data.TotalRecords, 1)
+	assertInt(t, "OutputTokens", data.ModelUsage["claude-opus-4-6"].OutputTokens, 80)
+	if data.ProjectUsage[projectSlug("/srv/api")] == nil {
+		t.Errorf("projects = %v, want one named after /srv/api", data.ProjectUsage)
+	}
+}
+
+func TestInputFiles_NotCached(t *testing.T) {
+	dir := t.TempDir()
+	writeTranscript(t, filepath.Join(dir, "a.jsonl"),
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0))
+	cacheDir := t.TempDir()
+
+	data, err := parseLogs(Options{Files: []string{filepath.Join(dir, "a.jsonl")}, CacheDir: cacheDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalRecords", data.TotalRecords, 1)
+	if entries, _ := os.ReadDir(cacheDir); len(entries) != 0 {
+		t.Errorf("-f files should not be cached, cache dir holds %d entries", len(entries))
+	}
+}
+
+func TestInputFiles_ProjectPerSession(t *testing.T) {
+	// What `cat projects/*/*.jsonl | goccc -` sees: sessions of different
+	// projects in one stream.
+	inSession := func(record, session, cwd string) string {
+		return withCwd(record[:len(record)-1]+`,"sessionId":"`+session+`"}`, cwd)
+	}
+	path := filepath.Join(t.TempDir(), "all.jsonl")
+	writeTranscript(t, path,
+		inSession(makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0), "s1", "/home/alice/webapp"),
+		inSession(makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 100, 50, 0, 0, 0), "s1", "/home/alice/webapp/src"),
+		inSession(makeRecord("req_3", "claude-opus-4-6", ts(0, 12), 100, 50, 0, 0, 0), "s2", "/home/alice/api"))
+
+	data, err := parseLogs(Options{Files: []string{path}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for cwd, want := range map[string]int{"/home/alice/webapp": 2, "/home/alice/api": 1} {
+		slug := projectSlug(cwd)
+		if b := data.ProjectUsage[slug]["claude-opus-4-6"]; b == nil || b.Requests != want {
+			t.Errorf("project %s = %+v, want %d requests", slug, b, want)
+		}
+		if got := data.ProjectPaths[slug]; got != cwd {
+			t.Errorf("ProjectPaths[%s] = %q, want %q", slug, got, cwd)
+		}
+	}
+	assertInt(t, "projects", len(data.ProjectUsage), 2)
+}
+
+func TestInputFiles_CutOffLastLine(t *testing.T) {
+	// Inputs cannot grow, so a last line without a newline is final.
+	line := makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 100, 50, 0, 0, 0)
+	content := makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0) + "\n" + line[:len(line)/2]
+	data, err := NewParser(Options{}).ParseReader(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalRecords", data.TotalRecords, 1)
+	assertInt(t, "ParseErrors", data.ParseErrors, 1)
+	if issues := data.Diagnostics.IssuesOf(IssueMalformed); len(issues) != 1 || issues[0].Line != 2 {
+		t.Errorf("malformed issues = %+v, want line 2", issues)
+	}
+}
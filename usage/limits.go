@@ -0,0 +1,78 @@
+package usage
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// LimitHit is a "Claude AI usage limit reached" message, which Claude Code
+// logs as a <synthetic> assistant record when a subscription plan runs out.
+type LimitHit struct {
+	Project   string
+	Session   string
+	Branch    string
+	Timestamp time.Time // zero when missing or unparseable
+	ResetAt   time.Time // zero when the message carries no reset time
+
+	order int // walk order of the source file, used when merging worker maps
+}
+
+// The reset time follows the pipe as a Unix timestamp.
+var limitMessage = regexp.MustCompile(`usage limit reached(?:\|(\d+))?`)
+
+func parseLimitHit(line []byte, rec *jsonRecord, path string, src fileSource, data *fileData) {
+	m := limitMessage.FindSubmatch(line)
+	if m == nil {
+		return
+	}
+	hit := &LimitHit{
+		Project: src.Project,
+		Session: src.session(rec.SessionID),
+		Branch:  rec.GitBranch,
+	}
+	if t, err := time.Parse(time.RFC3339, rec.Timestamp); err == nil {
+		hit.Timestamp = t
+	}
+	if len(m[1]) > 0 {
+		if epoch, err := strconv.ParseInt(string(m[1]), 10, 64); err == nil {
+			hit.ResetAt = time.Unix(epoch, 0)
+		}
+	}
+	id := rec.RequestID
+	if id == "" {
+		id = fmt.Sprintf("_noid_%s_%s", logFileID(path), rec.Timestamp)
+	}
+	data.Limits[id] = hit
+}
+
+func mergeLimitHit(dst map[string]*LimitHit, id string, h *LimitHit) {
+	if prev, ok := dst[id]; ok && prev.order > h.order {
+		return
+	}
+	dst[id] = h
+}
+
+func sortedLimitHits(hits map[string]*LimitHit) []LimitHit {
+	sorted := make([]LimitHit, 0, len(hits))
+	for _, h := range hits {
+		sorted = append(sorted, *h)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+	return sorted
+}
+
+// LimitsByWeek counts limit hits per week, keyed like the weekly breakdown.
+func (r *ParseResult) LimitsByWeek(week WeekOptions) map[string]int {
+	counts := make(map[string]int)
+	for _, h := range r.Limits {
+		key := "unknown"
+		if !h.Timestamp.IsZero() {
+			key = periodKey(r.clock.day(h.Timestamp), PeriodWeek, week)
+		}
+		counts[key]++
+	}
+	return counts
+}
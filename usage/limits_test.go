@@ -0,0 +1,59 @@
+package usage
+
+import (
+	"testing"
+	"time"
+)
+
+func makeLimitRecord(requestID, timestamp, text string) string {
+	return `{"type":"assistant","requestId":"` + requestID + `","sessionId":"s1","timestamp":"` + timestamp +
+		`","message":{"model":"<synthetic>","role":"assistant","content":[{"type":"text","text":"` + text +
+		`"}],"usage":{"input_tokens":0,"output_tokens":0}},"isApiErrorMessage":true}`
+}
+
+func TestLimitHits(t *testing.T) {
+	lines := []string{
+		makeRecord("req_1", "claude-opus-4-6", "2026-02-16T09:00:00Z", 100, 50, 0, 0, 0),
+		makeLimitRecord("req_limit_1", "2026-02-16T10:00:00Z", "Claude AI usage limit reached|1771250400"),
+		makeLimitRecord("req_limit_2", "2026-02-18T10:00:00Z", "Claude AI usage limit reached"),
+		makeLimitRecord("req_limit_3", "2026-02-24T10:00:00Z", "Claude AI usage limit reached|1771941600"),
+		// Other synthetic messages are not limit hits.
+		makeLimitRecord("req_other", "2026-02-24T11:00:00Z", "API Error: Request timed out"),
+	}
+	base := setupProject(t, "test-project", lines)
+
+	data, err := parseLogs(Options{BaseDir: base, Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 1 {
+		t.Errorf("TotalRecords = %d, want 1 (limit hits are not billed)", data.TotalRecords)
+	}
+	if len(data.Limits) != 3 {
+		t.Fatalf("got %d limit hits, want 3", len(data.Limits))
+	}
+	first := data.Limits[0]
+	if first.Session != "s1" || first.Project != "test-project" {
+		t.Errorf("first hit session/project = %q/%q", first.Session, first.Project)
+	}
+	if !first.ResetAt.Equal(time.Unix(1771250400, 0)) {
+		t.Errorf("first hit ResetAt = %v", first.ResetAt)
+	}
+	if !data.Limits[1].ResetAt.IsZero() {
+		t.Errorf("hit without reset time has ResetAt = %v", data.Limits[1].ResetAt)
+	}
+
+	weeks := data.LimitsByWeek(WeekOptions{Start: time.Monday})
+	if weeks["2026-02-16"] != 2 || weeks["2026-02-23"] != 1 {
+		t.Errorf("LimitsByWeek = %v, want 2026-02-16:2, 2026-02-23:1", weeks)
+	}
+
+	// The report's time window applies to limit hits too.
+	data, err = parseLogs(Options{BaseDir: base, Since: time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Limits) != 1 {
+		t.Errorf("got %d limit hits since 2026-02-20, want 1", len(data.Limits))
+	}
+}
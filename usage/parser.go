@@ -0,0 +1,1086 @@
+package usage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bucket sums the usage and cost of a group of requests: one model, day,
+// project and so on.
+type Bucket struct {
+	InputTokens  int
+	OutputTokens int
+	CacheRead    int
+	CacheWrite5m int
+	CacheWrite1h int
+	WebSearches  int
+	WebFetches   int
+	Cost         float64
+	Requests     int
+}
+
+// TotalCacheWrite is the sum of 5-minute and 1-hour cache writes.
+func (b *Bucket) TotalCacheWrite() int { return b.CacheWrite5m + b.CacheWrite1h }
+
+// CacheHitRatio is the share of prompt tokens served from cache.
+func (b *Bucket) CacheHitRatio() float64 {
+	prompt := b.InputTokens + b.CacheRead + b.TotalCacheWrite()
+	if prompt == 0 {
+		return 0
+	}
+	return float64(b.CacheRead) / float64(prompt)
+}
+
+func (b *Bucket) add(o *Bucket) {
+	b.InputTokens += o.InputTokens
+	b.OutputTokens += o.OutputTokens
+	b.CacheRead += o.CacheRead
+	b.CacheWrite5m += o.CacheWrite5m
+	b.CacheWrite1h += o.CacheWrite1h
+	b.WebSearches += o.WebSearches
+	b.WebFetches += o.WebFetches
+	b.Cost += o.Cost
+	b.Requests += o.Requests
+}
+
+// Threads separate the main conversation from work delegated to subagents.
+const (
+	ThreadMain     = "main"
+	ThreadSubagent = "subagent"
+)
+
+// SessionUsage aggregates one conversation, including its subagents.
+type SessionUsage struct {
+	Project string
+	First   time.Time
+	Last    time.Time
+	Models  map[string]*Bucket
+	Threads map[string]*Bucket // ThreadMain / ThreadSubagent
+}
+
+// Totals sums the session's requests and cost over all models.
+func (s *SessionUsage) Totals() (requests int, cost float64) {
+	for _, b := range s.Models {
+		requests += b.Requests
+		cost += b.Cost
+	}
+	return
+}
+
+// ParseResult is the aggregated usage of the logs a Parser read.
+type ParseResult struct {
+	ModelUsage   map[string]*Bucket
+	DailyUsage   map[string]map[string]*Bucket
+	ProjectUsage map[string]map[string]*Bucket
+	ProjectPaths map[string]string // project slug -> working directory, when the logs record it
+	SessionUsage map[string]*SessionUsage
+	BranchUsage  map[string]map[string]*Bucket // project slug -> git branch
+	ThreadUsage  map[string]map[string]*Bucket // project slug -> thread
+	VersionUsage map[string]*Bucket            // Claude Code version
+	TierUsage    map[string]*Bucket            // usage.service_tier ("" when not reported)
+	SourceUsage  map[string]*Bucket            // Source.Label
+	Sources      []Source                      // the sources read, in the order given
+	LongContext  Bucket                        // requests billed at a long-context threshold tier
+	Heatmap      [7][24]Bucket                 // [weekday of the report day][clock hour], in Options.Location
+	// AgentTypeUsage is subagent cost by the subagent_type of the Task call
+	// that spawned it ("" when the parent transcript doesn't record it).
+	AgentTypeUsage map[string]map[string]*Bucket // agent type -> model
+	ToolUsage      map[string]*ToolUsage         // tool name; "" for requests without tool calls
+	MCPServerUsage map[string]*ToolUsage         // MCP server name
+	TotalFiles     int
+	TotalRecords   int
+	ParseErrors    int
+	Diagnostics    Diagnostics
+	Limits         []LimitHit // usage limit messages, oldest first
+	Records        []*Record  // with Options.Records: every request counted, oldest first
+	Filters        []string   // active filters, as shown in the report header
+	Since          time.Time  // effective time window of the report; zero when unbounded
+	Until          time.Time
+	Duration       time.Duration
+	// CacheError is set when the parse cache could not be written. The
+	// result is complete regardless; only the next run is slower.
+	CacheError error
+
+	clock Clock
+}
+
+type jsonRecord struct {
+	Type      string `json:"type"`
+	RequestID string `json:"requestId"`
+	SessionID string `json:"sessionId"`
+	GitBranch string `json:"gitBranch"`
+	Sidechain bool   `json:"isSidechain"`
+	AgentID   string `json:"agentId"`
+	Version   string `json:"version"`
+	Cwd       string `json:"cwd"`
+	Timestamp string `json:"timestamp"`
+	Message   struct {
+		Model string `json:"model"`
+		Usage *Usage `json:"usage"`
+	} `json:"message"`
+}
+
+// Record is one API request after streaming duplicates have been merged:
+// the last log entry written for its request id.
+type Record struct {
+	RequestID string // "" when the log entry has none
+	Model     string
+	Project   string
+	Session   string
+	Branch    string
+	Subagent  bool
+	AgentID   string
+	Version   string
+	Tools     []ToolUse // only decoded when Options.Tools is set
+	Timestamp time.Time // zero when missing or unparseable
+	Usage     Usage
+
+	order  int    // walk order of the source file, used when merging worker maps
+	source string // label of the Source the record was read from
+}
+
+// Bucket returns a Bucket holding the record's usage and cost.
+func (r *Record) Bucket() Bucket {
+	cache5m, cache1h := r.Usage.CacheWriteTokens()
+	webSearch, webFetch := r.Usage.ServerToolRequests()
+	return Bucket{
+		InputTokens:  r.Usage.InputTokens,
+		OutputTokens: r.Usage.OutputTokens,
+		CacheRead:    r.Usage.CacheReadInputTokens,
+		CacheWrite5m: cache5m,
+		CacheWrite1h: cache1h,
+		WebSearches:  webSearch,
+		WebFetches:   webFetch,
+		Cost:         CalcCost(r.Model, r.Usage),
+		Requests:     1,
+	}
+}
+
+func (r *Record) date(c Clock) string {
+	if r.Timestamp.IsZero() {
+		return "unknown"
+	}
+	return c.day(r.Timestamp)
+}
+
+// fileState tracks how far a log file has been parsed, so a later pass can
+// resume at Offset instead of re-reading the whole file.
+type fileState struct {
+	Offset      int64 // bytes consumed; always at a line boundary
+	Records     int   // assistant records seen, used to number records without a requestId
+	ParseErrors int
+	Tools       bool    // tool_use content blocks are decoded
+	Lines       int     // lines consumed, for numbering Issues
+	Issues      []Issue // Path is filled in when the file's results are merged
+	// TailErrors and TailIssues belong to a trailing line without a newline,
+	// which the next pass reads again from Offset. They are reported with
+	// the others but start over on every pass.
+	TailErrors int
+	TailIssues []Issue
+}
+
+// fileData is everything collected from one log file.
+type fileData struct {
+	Records map[string]*Record
+	// Task tool calls and the subagents they spawned; see agents.go.
+	TaskTypes  map[string]string // tool_use id -> subagent_type
+	TaskAgents map[string]string // agentId -> tool_use id
+	Limits     map[string]*LimitHit
+	Cwd        string // working directory that best names the project; see preferCwd
+	// SessionCwds replaces Cwd for logs with no project directory, which
+	// may hold the sessions of several projects: session -> working directory.
+	SessionCwds map[string]string
+}
+
+func newFileData() *fileData {
+	return &fileData{
+		Records:    make(map[string]*Record),
+		TaskTypes:  make(map[string]string),
+		TaskAgents: make(map[string]string),
+		Limits:     make(map[string]*LimitHit),
+
+		SessionCwds: make(map[string]string),
+	}
+}
+
+// fileSource describes where a log file's records belong, as derived from
+// its location under the projects directory.
+type fileSource struct {
+	Project string
+	Session string // for subagent transcripts, the parent session
+	// Subagent transcripts take their session from the path, so they always
+	// roll up into the parent conversation.
+	Subagent bool
+	Agent    string // agentId from an agent-<id>.jsonl file name
+	// Compressed archives can't be resumed, so they are reparsed whole.
+	Compressed bool
+}
+
+// session returns the session a record belongs to, given its sessionId.
+func (s fileSource) session(recordSession string) string {
+	if recordSession != "" && !s.Subagent {
+		return recordSession
+	}
+	return s.Session
+}
+
+// sourceForPath derives the fileSource of a log file from its path relative
+// to the projects directory: <slug>/<session>.jsonl or
+// <slug>/<session>/subagents/<agent>.jsonl, optionally compressed.
+func sourceForPath(rel string) fileSource {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	n := len(parts)
+	name, _ := logBaseName(parts[n-1])
+	name = strings.TrimSuffix(name, ".jsonl")
+	src := fileSource{Project: parts[0], Compressed: isCompressed(parts[n-1])}
+	if n >= 4 && parts[n-2] == "subagents" {
+		src.Session = parts[n-3]
+		src.Subagent = true
+		src.Agent = strings.TrimPrefix(name, "agent-")
+	} else {
+		src.Session = name
+	}
+	return src
+}
+
+func parseFile(path string, src fileSource, state *fileState, data *fileData) error {
+	f, err := openLog(path)
+	if err != nil {
+		return err
+	}
+	return parseStream(f, path, src, state, data, false)
+}
+
+// parseStream parses an opened log and closes it. path only names the log
+// in issues and generated record ids. final means the log cannot grow
+// before it is read again, so a trailing line without a newline is
+// complete.
+func parseStream(f io.ReadCloser, path string, src fileSource, state *fileState, data *fileData, final bool) error {
+	defer func() { _ = f.Close() }()
+	state.TailErrors, state.TailIssues = 0, nil
+
+	if state.Offset > 0 {
+		seeker, ok := f.(io.Seeker)
+		if !ok {
+			return fmt.Errorf("cannot resume %s at offset %d", path, state.Offset)
+		}
+		if _, err := seeker.Seek(state.Offset, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	var lineLen int
+	var partial bool
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 100*1024*1024)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		lineLen = advance
+		partial = token != nil && atEOF && (advance == 0 || data[advance-1] != '\n')
+		return advance, token, err
+	})
+	for scanner.Scan() {
+		if partial && !final {
+			// The trailing line may still be being written. Take it if it is
+			// already valid, but leave Offset before it so the next pass
+			// reads it again in full. A crash can leave it cut off for good,
+			// so its problems are reported all the same.
+			tail := *state
+			tail.Lines++
+			tail.ParseErrors, tail.Issues = 0, nil
+			parseLine(scanner.Bytes(), path, src, &tail, data)
+			state.TailErrors, state.TailIssues = tail.ParseErrors, tail.Issues
+			continue
+		}
+		state.Offset += int64(lineLen)
+		state.Lines++
+		parseLine(scanner.Bytes(), path, src, state, data)
+	}
+	return scanner.Err()
+}
+
+func parseLine(line []byte, path string, src fileSource, state *fileState, data *fileData) {
+	if len(line) == 0 {
+		return
+	}
+
+	if !bytes.Contains(line, []byte(`"type":"assistant"`)) && !bytes.Contains(line, []byte(`"type": "assistant"`)) {
+		if bytes.Contains(line, []byte(`"toolUseResult"`)) && bytes.Contains(line, []byte(`"agentId"`)) {
+			parseTaskResult(line, data)
+		}
+		return
+	}
+
+	var rec jsonRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		state.ParseErrors++
+		addIssue(state, IssueMalformed, err.Error())
+		return
+	}
+	if bytes.Contains(line, []byte(`"subagent_type"`)) {
+		parseTaskCalls(line, data)
+	}
+	var tools []ToolUse
+	if state.Tools && bytes.Contains(line, []byte(`"tool_use"`)) {
+		tools = parseToolUses(line)
+	}
+	if rec.Message.Usage == nil || rec.Message.Model == "" {
+		return
+	}
+	if src.Project == "" {
+		if rec.Cwd != "" {
+			session := src.session(rec.SessionID)
+			data.SessionCwds[session] = preferCwd("", data.SessionCwds[session], rec.Cwd)
+		}
+	} else if rec.Cwd != data.Cwd {
+		data.Cwd = preferCwd(src.Project, data.Cwd, rec.Cwd)
+	}
+	if rec.Message.Model == "<synthetic>" {
+		if bytes.Contains(line, []byte("usage limit reached")) {
+			parseLimitHit(line, &rec, path, src, data)
+		}
+		return
+	}
+
+	var timestamp time.Time
+	if rec.Timestamp != "" {
+		parsed, err := time.Parse(time.RFC3339, rec.Timestamp)
+		if err == nil {
+			timestamp = parsed
+		} else {
+			state.ParseErrors++
+			addIssue(state, IssueBadTimestamp, rec.Timestamp)
+		}
+	} else {
+		addIssue(state, IssueMissingTimestamp, "")
+	}
+
+	state.Records++
+	requestID := rec.RequestID
+	if requestID == "" {
+		requestID = fmt.Sprintf("_noid_%s_%d", logFileID(path), state.Records)
+	}
+
+	agentID := rec.AgentID
+	if agentID == "" {
+		agentID = src.Agent
+	}
+
+	// Streaming writes one entry per content block, so tool calls are
+	// spread over the entries of a request.
+	if prev, ok := data.Records[requestID]; ok {
+		tools = mergeToolUses(prev.Tools, tools)
+	}
+
+	data.Records[requestID] = &Record{
+		RequestID: rec.RequestID,
+		Model:     rec.Message.Model,
+		Project:   src.Project,
+		Session:   src.session(rec.SessionID),
+		Branch:    rec.GitBranch,
+		Subagent:  src.Subagent || rec.Sidechain,
+		AgentID:   agentID,
+		Version:   rec.Version,
+		Tools:     tools,
+		Timestamp: timestamp,
+		Usage:     *rec.Message.Usage,
+	}
+}
+
+// Options configures a Parser.
+type Options struct {
+	BaseDir       string
+	Days          int
+	Since         time.Time      // inclusive lower bound; zero for none
+	Until         time.Time      // exclusive upper bound; zero for none
+	Location      *time.Location // zone for daily buckets, the heatmap and -days; nil uses local time
+	DayStart      time.Duration  // offset of the day boundary from midnight
+	ProjectFilter string
+	BranchFilter  string // glob matched against the record's git branch
+	// Further filters, applied together with ProjectFilter; see recordFilter.
+	ProjectRegex        *regexp.Regexp
+	ExcludeProject      string
+	ExcludeProjectRegex *regexp.Regexp
+	ModelFilter         string // substring of the model id or display name
+	ModelRegex          *regexp.Regexp
+	Tools               bool   // decode tool_use content blocks for ToolUsage
+	Records             bool   // keep the deduplicated records in ParseResult.Records
+	Jobs                int    // concurrent file parsers; <= 0 uses runtime.NumCPU()
+	CacheDir            string // directory for the persistent parse cache; "" disables it
+	ArchiveDir          string // also read logs archived here by `goccc archive`; "" for none
+	// Sources, when set, replaces BaseDir and ArchiveDir with several data
+	// directories. Records are deduplicated across all of them.
+	Sources []Source
+	// Files, when set, are read instead of any projects directory: paths,
+	// glob patterns, or "-" for standard input. They are not cached, since
+	// nothing would ever prune their entries.
+	Files []string
+
+	logs []logFile // set by ParseFS and ParseReader; read instead of all of the above
+}
+
+// Parser reads Claude Code logs and aggregates their usage. Its Options
+// select where logs are read from and which records are kept.
+type Parser struct {
+	opts Options
+}
+
+// NewParser returns a Parser configured by opts.
+func NewParser(opts Options) *Parser {
+	return &Parser{opts: opts}
+}
+
+// Parse reads the projects directories of the configured sources, or
+// Options.Files when set.
+func (p *Parser) Parse() (*ParseResult, error) {
+	return parseLogs(p.opts)
+}
+
+// ParseFS reads the logs in fsys, which is laid out like a projects
+// directory: one directory per project holding session logs and their
+// subagents/ transcripts. Logs at the root of fsys are attributed to the
+// project of the working directory they record. Sources, Files and the
+// parse cache are not used.
+func (p *Parser) ParseFS(fsys fs.FS) (*ParseResult, error) {
+	logs, err := fsLogs(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return p.parseLogs(logs)
+}
+
+// ParseReader reads a single session log from r. Its project is taken from
+// the working directory recorded in the log.
+func (p *Parser) ParseReader(r io.Reader) (*ParseResult, error) {
+	return p.parseLogs([]logFile{{
+		fileSource: fileSource{Session: readerName},
+		path:       readerName,
+		open:       func() (io.ReadCloser, error) { return io.NopCloser(r), nil },
+	}})
+}
+
+func (p *Parser) parseLogs(logs []logFile) (*ParseResult, error) {
+	if logs == nil {
+		logs = []logFile{}
+	}
+	opts := p.opts
+	opts.Files, opts.CacheDir, opts.logs = nil, "", logs
+	return parseLogs(opts)
+}
+
+func (o Options) clock() Clock {
+	return Clock{Location: o.Location, DayStart: o.DayStart}
+}
+
+type logFile struct {
+	fileSource
+	path    string
+	source  string // Source.Label
+	order   int
+	size    int64
+	modTime time.Time
+	// open, when set, opens a log that is not a file on disk: one inside an
+	// fs.FS or handed to Parser.ParseReader. Such logs are never cached.
+	open func() (io.ReadCloser, error)
+	// root is the projects directory the log was found in, which groups its
+	// cache entry; "" for logs outside one, which are not cached.
+	root string
+}
+
+// final reports whether the log is read whole, with no later pass to pick
+// up what is appended: archives, and logs outside a projects directory.
+func (f logFile) final() bool {
+	return f.Compressed || f.root == ""
+}
+
+func (f logFile) reader() (io.ReadCloser, error) {
+	if f.open != nil {
+		return f.open()
+	}
+	return openLog(f.path)
+}
+
+func parseLogs(opts Options) (*ParseResult, error) {
+	clock := opts.clock()
+	cutoff := opts.Since
+	if opts.Days > 0 {
+		if days := clock.startOfDay(time.Now()).AddDate(0, 0, -(opts.Days - 1)); days.After(cutoff) {
+			cutoff = days
+		}
+	}
+	until := opts.Until
+
+	type projectsDir struct {
+		path   string
+		source string
+	}
+	var sources []Source
+	if len(opts.Files) == 0 && opts.logs == nil {
+		sources = opts.sources()
+	}
+	var dirs []projectsDir
+	for _, src := range sources {
+		dir := filepath.Join(src.BaseDir, "projects")
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("no projects directory found at %s", dir)
+		}
+		dirs = append(dirs, projectsDir{dir, src.Label})
+		if src.ArchiveDir != "" {
+			// Nothing archived yet is not an error.
+			archived := filepath.Join(src.ArchiveDir, "projects")
+			if info, err := os.Stat(archived); err == nil && info.IsDir() {
+				dirs = append(dirs, projectsDir{archived, src.Label})
+			}
+		}
+	}
+	// Cache entries are keyed by path, which must not depend on the working
+	// directory. A directory listed twice is read once.
+	seenDirs := make(map[string]bool)
+	unique := dirs[:0]
+	for _, dir := range dirs {
+		if abs, err := filepath.Abs(dir.path); err == nil {
+			dir.path = abs
+		}
+		if !seenDirs[dir.path] {
+			seenDirs[dir.path] = true
+			unique = append(unique, dir)
+		}
+	}
+	dirs = unique
+
+	var cache *parseCache
+	if opts.CacheDir != "" && len(opts.Files) == 0 {
+		cache = openCache(opts.CacheDir)
+	}
+
+	var files []logFile
+	var walkIssues []Issue
+	projFilter := newProjectFilter(opts.ProjectFilter)
+	hasCutoff := !cutoff.IsZero()
+	hasUntil := !until.IsZero()
+
+	walk := func(projectsDir, source string) error {
+		return filepath.WalkDir(projectsDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				walkIssues = append(walkIssues, Issue{Kind: IssueUnreadable, Path: path, Detail: err.Error()})
+				return nil
+			}
+
+			if d.IsDir() {
+				if path == projectsDir {
+					return nil
+				}
+				if opts.ProjectFilter != "" {
+					rel, _ := filepath.Rel(projectsDir, path)
+					slug := strings.SplitN(rel, string(filepath.Separator), 2)[0]
+					if !projFilter.mayMatch(slug) {
+						return fs.SkipDir
+					}
+				}
+				return nil
+			}
+
+			if _, ok := logBaseName(d.Name()); !ok {
+				return nil
+			}
+
+			var size int64
+			var modTime time.Time
+			if hasCutoff || cache != nil {
+				if info, err := d.Info(); err == nil {
+					if hasCutoff && info.ModTime().Before(cutoff) {
+						return nil
+					}
+					size, modTime = info.Size(), info.ModTime()
+				}
+			}
+
+			rel, err := filepath.Rel(projectsDir, path)
+			if err != nil {
+				return nil
+			}
+
+			files = append(files, logFile{fileSource: sourceForPath(rel), path: path, root: projectsDir, source: source, order: len(files), size: size, modTime: modTime})
+			return nil
+		})
+	}
+	for _, dir := range dirs {
+		if err := walk(dir.path, dir.source); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.Files) > 0 {
+		var err error
+		if files, err = inputFiles(opts.Files); err != nil {
+			return nil, err
+		}
+	}
+	if opts.logs != nil {
+		files = opts.logs
+	}
+	orderArchivesFirst(files)
+
+	keep := func(r *Record) bool {
+		if hasCutoff && (r.Timestamp.IsZero() || r.Timestamp.Before(cutoff)) {
+			return false
+		}
+		if hasUntil && (r.Timestamp.IsZero() || !r.Timestamp.Before(until)) {
+			return false
+		}
+		if opts.BranchFilter != "" {
+			if ok, _ := path.Match(opts.BranchFilter, r.Branch); !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	parsed := parseFiles(files, keep, opts, cache)
+	deduped, agentTypes := parsed.deduped, parsed.agentTypes
+	// The walk only saw slugs; now that the real paths are known, drop
+	// projects whose path doesn't match after all. Limit hits carry no
+	// model, so only the project filters apply to them.
+	if filter := newRecordFilter(opts); filter.active() {
+		for id, r := range deduped {
+			if !filter.keepProject(r.Project, parsed.cwds[r.Project]) || !filter.keepModel(r.Model) {
+				delete(deduped, id)
+			}
+		}
+		for id, h := range parsed.limits {
+			if !filter.keepProject(h.Project, parsed.cwds[h.Project]) {
+				delete(parsed.limits, id)
+			}
+		}
+	}
+
+	cacheErr := parsed.cacheErr
+	if cache != nil && opts.ProjectFilter == "" && !hasCutoff {
+		for _, dir := range dirs {
+			if err := cache.prune(dir.path, files); err != nil && cacheErr == nil {
+				cacheErr = err
+			}
+		}
+	}
+
+	result := &ParseResult{
+		ModelUsage:   make(map[string]*Bucket),
+		DailyUsage:   make(map[string]map[string]*Bucket),
+		ProjectUsage: make(map[string]map[string]*Bucket),
+		ProjectPaths: make(map[string]string),
+		SessionUsage: make(map[string]*SessionUsage),
+		BranchUsage:  make(map[string]map[string]*Bucket),
+		ThreadUsage:  make(map[string]map[string]*Bucket),
+		VersionUsage: make(map[string]*Bucket),
+		TierUsage:    make(map[string]*Bucket),
+		SourceUsage:  make(map[string]*Bucket),
+		Sources:      sources,
+		ToolUsage:    make(map[string]*ToolUsage),
+		TotalFiles:   len(files),
+		TotalRecords: len(deduped),
+		ParseErrors:  parsed.parseErrors,
+		Filters:      describeFilters(opts),
+		Since:        cutoff,
+		Until:        until,
+		CacheError:   cacheErr,
+		clock:        clock,
+
+		AgentTypeUsage: make(map[string]map[string]*Bucket),
+		MCPServerUsage: make(map[string]*ToolUsage),
+	}
+
+	result.Diagnostics.Issues = append(walkIssues, parsed.issues...)
+	sortIssues(result.Diagnostics.Issues)
+	result.Diagnostics.UnmatchedModels = make(map[string]*Bucket)
+
+	for slug, cwd := range parsed.cwds {
+		if cwd != "" {
+			result.ProjectPaths[slug] = cwd
+		}
+	}
+
+	for _, h := range sortedLimitHits(parsed.limits) {
+		if keep(&Record{Timestamp: h.Timestamp, Branch: h.Branch}) {
+			result.Limits = append(result.Limits, h)
+		}
+	}
+	if opts.Records {
+		result.Records = sortedRecords(deduped)
+	}
+
+	for _, r := range deduped {
+		rb := r.Bucket()
+
+		session := getOrCreateSession(result.SessionUsage, r.Session, r.Project)
+		if !r.Timestamp.IsZero() {
+			if session.First.IsZero() || r.Timestamp.Before(session.First) {
+				session.First = r.Timestamp
+			}
+			if r.Timestamp.After(session.Last) {
+				session.Last = r.Timestamp
+			}
+		}
+
+		buckets := []*Bucket{
+			getOrCreateBucket(result.ModelUsage, r.Model),
+			getOrCreateNestedBucket(result.DailyUsage, r.date(clock), r.Model),
+			getOrCreateNestedBucket(result.ProjectUsage, r.Project, r.Model),
+			getOrCreateBucket(session.Models, r.Model),
+			getOrCreateNestedBucket(result.BranchUsage, r.Project, r.Branch),
+			getOrCreateBucket(result.VersionUsage, r.Version),
+			getOrCreateBucket(result.TierUsage, r.Usage.ServiceTier),
+			getOrCreateBucket(result.SourceUsage, r.source),
+		}
+
+		if r.Timestamp.IsZero() {
+			result.Diagnostics.UnknownDates++
+		}
+		if _, ok := LookupPricing(r.Model); !ok {
+			buckets = append(buckets, getOrCreateBucket(result.Diagnostics.UnmatchedModels, r.Model))
+		}
+
+		if isLongContext(r.Model, r.Usage) {
+			buckets = append(buckets, &result.LongContext)
+		}
+
+		if !r.Timestamp.IsZero() {
+			t := r.Timestamp.In(clock.location())
+			day := t.Add(-clock.DayStart).Weekday()
+			buckets = append(buckets, &result.Heatmap[day][t.Hour()])
+		}
+
+		thread := ThreadMain
+		if r.Subagent {
+			thread = ThreadSubagent
+			buckets = append(buckets, getOrCreateNestedBucket(result.AgentTypeUsage, agentTypes[r.AgentID], r.Model))
+		}
+		buckets = append(buckets,
+			getOrCreateNestedBucket(result.ThreadUsage, r.Project, thread),
+			getOrCreateBucket(session.Threads, thread),
+		)
+
+		if opts.Tools {
+			addToolUsage(result.ToolUsage, result.MCPServerUsage, r.Tools, rb.Cost)
+		}
+
+		for _, b := range buckets {
+			b.add(&rb)
+		}
+	}
+
+	return result, nil
+}
+
+// parsedFiles is what parseFiles collects from all log files.
+type parsedFiles struct {
+	deduped     map[string]*Record
+	agentTypes  map[string]string // subagent id -> type of the Task call that spawned it
+	limits      map[string]*LimitHit
+	issues      []Issue
+	cwds        map[string]string // project slug -> working directory
+	sessionCwds map[string]string // session -> working directory, for logs with no project directory
+	parseErrors int
+	cacheErr    error // the first cache entry that could not be written
+}
+
+// parseFiles parses files on a bounded worker pool. Each worker dedups into
+// its own map; records carry the walk order of their file so merging keeps
+// the same last-entry-wins result as parsing the files one after another.
+// Records rejected by keep are dropped at merge time, so cached entries stay
+// valid for any date range or filter.
+func parseFiles(files []logFile, keep func(*Record) bool, opts Options, cache *parseCache) parsedFiles {
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	jobs = max(1, min(jobs, len(files)))
+
+	results := make([]parsedFiles, jobs)
+	queue := make(chan logFile)
+
+	var wg sync.WaitGroup
+	for i := range results {
+		res := &results[i]
+		res.deduped = make(map[string]*Record)
+		res.agentTypes = make(map[string]string)
+		res.limits = make(map[string]*LimitHit)
+		res.cwds = make(map[string]string)
+		res.sessionCwds = make(map[string]string)
+		wg.Go(func() {
+			for f := range queue {
+				data, state, updated, fErr := parseLogFile(f, opts.Tools, cache)
+				if fErr != nil {
+					res.issues = append(res.issues, Issue{Kind: IssueUnreadable, Path: f.path, Detail: fErr.Error()})
+				} else {
+					res.parseErrors += state.ParseErrors + state.TailErrors
+					for _, is := range slices.Concat(state.Issues, state.TailIssues) {
+						is.Path = f.path
+						res.issues = append(res.issues, is)
+					}
+					if updated {
+						err := cache.store(f, &cacheEntry{Size: f.size, ModTime: f.modTime, State: state, Data: data})
+						if err != nil && res.cacheErr == nil {
+							res.cacheErr = err
+						}
+					}
+				}
+				data.resolveAgentTypes(res.agentTypes)
+				// Records and limit hits were stored in the cache entry as
+				// parsed, so the per-run fields are set on copies.
+				for id, r := range data.Records {
+					if !keep(r) {
+						continue
+					}
+					rec := *r
+					rec.order = f.order
+					rec.source = f.source
+					mergeRecord(res.deduped, id, &rec)
+				}
+				if f.Project != "" {
+					res.cwds[f.Project] = preferCwd(f.Project, res.cwds[f.Project], data.Cwd)
+				}
+				for session, cwd := range data.SessionCwds {
+					res.sessionCwds[session] = preferCwd("", res.sessionCwds[session], cwd)
+				}
+				for id, h := range data.Limits {
+					hit := *h
+					hit.order = f.order
+					mergeLimitHit(res.limits, id, &hit)
+				}
+			}
+		})
+	}
+	for _, f := range files {
+		queue <- f
+	}
+	close(queue)
+	wg.Wait()
+
+	merged := results[0]
+	for _, res := range results[1:] {
+		mergeDeduped(merged.deduped, res.deduped)
+		maps.Copy(merged.agentTypes, res.agentTypes)
+		for id, h := range res.limits {
+			mergeLimitHit(merged.limits, id, h)
+		}
+		for slug, cwd := range res.cwds {
+			merged.cwds[slug] = preferCwd(slug, merged.cwds[slug], cwd)
+		}
+		for session, cwd := range res.sessionCwds {
+			merged.sessionCwds[session] = preferCwd("", merged.sessionCwds[session], cwd)
+		}
+		merged.issues = append(merged.issues, res.issues...)
+		merged.parseErrors += res.parseErrors
+		if merged.cacheErr == nil {
+			merged.cacheErr = res.cacheErr
+		}
+	}
+
+	// Records from logs with no project directory are named after their
+	// session's working directory, now that every file of the session has
+	// been read. The records and hits are already copies.
+	for _, r := range merged.deduped {
+		if r.Project == "" {
+			r.Project = merged.inputProject(r.Session)
+		}
+	}
+	for _, h := range merged.limits {
+		if h.Project == "" {
+			h.Project = merged.inputProject(h.Session)
+		}
+	}
+	return merged
+}
+
+// inputProject returns the project of a session read from a log with no
+// project directory, and records its working directory under it.
+func (p *parsedFiles) inputProject(session string) string {
+	cwd := p.sessionCwds[session]
+	project := inputProject(cwd)
+	p.cwds[project] = preferCwd(project, p.cwds[project], cwd)
+	return project
+}
+
+// parseLogFile returns the parsed data of a single file, reusing or resuming
+// its cache entry when possible. updated reports whether the data differs
+// from what the cache holds.
+func parseLogFile(f logFile, tools bool, cache *parseCache) (data *fileData, state fileState, updated bool, err error) {
+	data = newFileData()
+	state.Tools = tools
+	// Entries parsed without tool_use blocks can't serve a tools report.
+	if e := cache.lookup(f); e != nil && (e.State.Tools || !tools) {
+		if e.Size == f.size && e.ModTime.Equal(f.modTime) {
+			return e.Data, e.State, false, nil
+		}
+		// Logs are append-only: a grown file only needs its new bytes parsed.
+		if f.size > e.Size && !f.Compressed {
+			state, data = e.State, e.Data
+		}
+	}
+	r, err := f.reader()
+	if err != nil {
+		return data, state, false, err
+	}
+	err = parseStream(r, f.path, f.fileSource, &state, data, f.final())
+	return data, state, cache.entryPath(f) != "" && !f.modTime.IsZero(), err
+}
+
+// logFileID names a log file in the ids of records without a requestId. The
+// compression suffix is dropped, so an archived copy of a log yields the same
+// ids as the log itself and the two dedup against each other.
+func logFileID(path string) string {
+	base, _ := logBaseName(filepath.Base(path))
+	return base
+}
+
+// orderArchivesFirst moves compressed logs ahead of plain ones and renumbers
+// the walk order. An archive is an older copy of a log, so when both are
+// present the live file's entries must win the dedup.
+func orderArchivesFirst(files []logFile) {
+	slices.SortStableFunc(files, func(a, b logFile) int {
+		switch {
+		case a.Compressed == b.Compressed:
+			return 0
+		case a.Compressed:
+			return -1
+		}
+		return 1
+	})
+	for i := range files {
+		files[i].order = i
+	}
+}
+
+// mergeDeduped copies src into dst, keeping the record from the later file
+// when a requestId appears in both.
+func mergeDeduped(dst, src map[string]*Record) {
+	for id, r := range src {
+		mergeRecord(dst, id, r)
+	}
+}
+
+// sortedRecords lists records by timestamp, then request id, so exports are
+// stable from run to run. Records without a timestamp come first.
+func sortedRecords(records map[string]*Record) []*Record {
+	sorted := make([]*Record, 0, len(records))
+	for _, r := range records {
+		sorted = append(sorted, r)
+	}
+	slices.SortFunc(sorted, func(a, b *Record) int {
+		if c := a.Timestamp.Compare(b.Timestamp); c != 0 {
+			return c
+		}
+		return strings.Compare(a.RequestID, b.RequestID)
+	})
+	return sorted
+}
+
+func mergeRecord(dst map[string]*Record, id string, r *Record) {
+	if prev, ok := dst[id]; ok && prev.order > r.order {
+		return
+	}
+	dst[id] = r
+}
+
+func getOrCreateBucket(m map[string]*Bucket, key string) *Bucket {
+	if b, ok := m[key]; ok {
+		return b
+	}
+	b := &Bucket{}
+	m[key] = b
+	return b
+}
+
+func getOrCreateNestedBucket(m map[string]map[string]*Bucket, outerKey, innerKey string) *Bucket {
+	inner, ok := m[outerKey]
+	if !ok {
+		inner = make(map[string]*Bucket)
+		m[outerKey] = inner
+	}
+	return getOrCreateBucket(inner, innerKey)
+}
+
+func getOrCreateSession(m map[string]*SessionUsage, id, project string) *SessionUsage {
+	if s, ok := m[id]; ok {
+		return s
+	}
+	s := &SessionUsage{Project: project, Models: make(map[string]*Bucket), Threads: make(map[string]*Bucket)}
+	m[id] = s
+	return s
+}
+
+// UsageTotals are the grand totals of a ParseResult. CacheW is the sum of
+// CacheW5m and CacheW1h.
+type UsageTotals struct {
+	Cost     float64
+	Input    int
+	Output   int
+	CacheR   int
+	CacheW   int
+	CacheW5m int
+	CacheW1h int
+	Searches int
+	Fetches  int
+	Requests int
+}
+
+// DateRange returns the first and last report day with usage, formatted as
+// 2006-01-02, or empty strings when there is none.
+func (r *ParseResult) DateRange() (from, to string) {
+	for d := range r.DailyUsage {
+		if d == "unknown" {
+			continue
+		}
+		if from == "" || d < from {
+			from = d
+		}
+		if to == "" || d > to {
+			to = d
+		}
+	}
+	return
+}
+
+// ThreadTotals sums ThreadUsage across projects.
+func (r *ParseResult) ThreadTotals() (mainThread, subagents Bucket) {
+	for _, threads := range r.ThreadUsage {
+		if b, ok := threads[ThreadMain]; ok {
+			mainThread.add(b)
+		}
+		if b, ok := threads[ThreadSubagent]; ok {
+			subagents.add(b)
+		}
+	}
+	return
+}
+
+// Totals sums usage and cost over every model.
+func (r *ParseResult) Totals() UsageTotals {
+	var t UsageTotals
+	for _, b := range r.ModelUsage {
+		t.Cost += b.Cost
+		t.Input += b.InputTokens
+		t.Output += b.OutputTokens
+		t.CacheR += b.CacheRead
+		t.CacheW5m += b.CacheWrite5m
+		t.CacheW1h += b.CacheWrite1h
+		t.Searches += b.WebSearches
+		t.Fetches += b.WebFetches
+		t.Requests += b.Requests
+	}
+	t.CacheW = t.CacheW5m + t.CacheW1h
+	return t
+}
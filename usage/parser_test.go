@@ -0,0 +1,1117 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// makeRecord builds a JSONL line for an assistant message.
+func makeRecord(requestID, model, timestamp string, input, output, cacheRead, cacheWrite5m, cacheWrite1h int) string {
+	type cacheCreation struct {
+		Ephemeral5m int `json:"ephemeral_5m_input_tokens"`
+		Ephemeral1h int `json:"ephemeral_1h_input_tokens"`
+	}
+	type usage struct {
+		InputTokens              int            `json:"input_tokens"`
+		OutputTokens             int            `json:"output_tokens"`
+		CacheReadInputTokens     int            `json:"cache_read_input_tokens"`
+		CacheCreationInputTokens int            `json:"cache_creation_input_tokens"`
+		CacheCreation            *cacheCreation `json:"cache_creation,omitempty"`
+	}
+	type message struct {
+		Model string `json:"model"`
+		Role  string `json:"role"`
+		Usage usage  `json:"usage"`
+	}
+	type record struct {
+		Type      string  `json:"type"`
+		RequestID string  `json:"requestId"`
+		Timestamp string  `json:"timestamp"`
+		Message   message `json:"message"`
+	}
+
+	var cc *cacheCreation
+	if cacheWrite5m > 0 || cacheWrite1h > 0 {
+		cc = &cacheCreation{Ephemeral5m: cacheWrite5m, Ephemeral1h: cacheWrite1h}
+	}
+
+	rec := record{
+		Type:      "assistant",
+		RequestID: requestID,
+		Timestamp: timestamp,
+		Message: message{
+			Model: model,
+			Role:  "assistant",
+			Usage: usage{
+				InputTokens:              input,
+				OutputTokens:             output,
+				CacheReadInputTokens:     cacheRead,
+				CacheCreationInputTokens: cacheWrite5m + cacheWrite1h,
+				CacheCreation:            cc,
+			},
+		},
+	}
+	b, _ := json.Marshal(rec)
+	return string(b)
+}
+
+func makeUserRecord(timestamp string) string {
+	return fmt.Sprintf(`{"type":"user","message":{"role":"user","content":"hello"},"timestamp":%q}`, timestamp)
+}
+
+func localMidnight() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+func ts(daysAgo int, hour int) string {
+	t := localMidnight().AddDate(0, 0, -daysAgo).Add(time.Duration(hour) * time.Hour)
+	return t.Format(time.RFC3339)
+}
+
+func dateStr(daysAgo int) string {
+	return localMidnight().AddDate(0, 0, -daysAgo).Format("2006-01-02")
+}
+
+// setupProject creates a project dir with JSONL lines and returns the base dir.
+func setupProject(t *testing.T, projectName string, lines []string) string {
+	t.Helper()
+	base := t.TempDir()
+	return addProject(t, base, projectName, lines)
+}
+
+// addProject adds a project's JSONL lines to an existing base dir.
+func addProject(t *testing.T, base, projectName string, lines []string) string {
+	t.Helper()
+	projDir := filepath.Join(base, "projects", projectName)
+	if err := os.MkdirAll(projDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := ""
+	for _, l := range lines {
+		content += l + "\n"
+	}
+	if err := os.WriteFile(filepath.Join(projDir, "session.jsonl"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return base
+}
+
+// --- Deduplication ---
+
+func TestDeduplicate_StreamingUpdates(t *testing.T) {
+	// Same requestID appears twice (streaming) — last value wins
+	lines := []string{
+		makeRecord("req_001", "claude-opus-4-6", ts(0, 10), 100, 10, 500, 200, 0),
+		makeRecord("req_001", "claude-opus-4-6", ts(0, 10), 100, 50, 500, 200, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 1 {
+		t.Errorf("expected 1 deduped record, got %d", data.TotalRecords)
+	}
+	opus := data.ModelUsage["claude-opus-4-6"]
+	if opus == nil {
+		t.Fatal("expected opus bucket")
+	}
+	if opus.OutputTokens != 50 {
+		t.Errorf("expected output_tokens=50 (last value wins), got %d", opus.OutputTokens)
+	}
+}
+
+func TestDeduplicate_DifferentRequests(t *testing.T) {
+	lines := []string{
+		makeRecord("req_001", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		makeRecord("req_002", "claude-opus-4-6", ts(0, 11), 200, 100, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 2 {
+		t.Errorf("expected 2 records, got %d", data.TotalRecords)
+	}
+	opus := data.ModelUsage["claude-opus-4-6"]
+	if opus.InputTokens != 300 {
+		t.Errorf("expected aggregated input=300, got %d", opus.InputTokens)
+	}
+	if opus.OutputTokens != 150 {
+		t.Errorf("expected aggregated output=150, got %d", opus.OutputTokens)
+	}
+}
+
+func TestDeduplicate_SameRequestAcrossProjects(t *testing.T) {
+	// Same requestID in two projects — last write wins (map semantics)
+	base := setupProject(t, "project-a", []string{
+		makeRecord("req_001", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	addProject(t, base, "project-b", []string{
+		makeRecord("req_001", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Same requestID should deduplicate to 1 record
+	if data.TotalRecords != 1 {
+		t.Errorf("expected 1 deduped record across projects, got %d", data.TotalRecords)
+	}
+}
+
+func TestParallelMatchesSerial(t *testing.T) {
+	// Overlapping requestIDs across projects: later files in walk order must
+	// win regardless of which worker parsed them.
+	base := t.TempDir()
+	for p := 0; p < 12; p++ {
+		var lines []string
+		for i := 0; i < 20; i++ {
+			lines = append(lines, makeRecord(
+				fmt.Sprintf("req_%d", (p*7+i)%40), "claude-opus-4-6", ts(i%3, 10), 100*(p+1), 10*(i+1), 0, 0, 0,
+			))
+		}
+		addProject(t, base, fmt.Sprintf("project-%02d", p), lines)
+	}
+
+	serial, err := parseLogs(Options{BaseDir: base, Jobs: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, jobs := range []int{2, 4, 16} {
+		parallel, err := parseLogs(Options{BaseDir: base, Jobs: jobs})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parallel.TotalRecords != serial.TotalRecords || parallel.TotalFiles != serial.TotalFiles {
+			t.Errorf("jobs=%d: records/files = %d/%d, want %d/%d", jobs,
+				parallel.TotalRecords, parallel.TotalFiles, serial.TotalRecords, serial.TotalFiles)
+		}
+		for slug, models := range serial.ProjectUsage {
+			for model, want := range models {
+				got := parallel.ProjectUsage[slug][model]
+				if got == nil {
+					t.Fatalf("jobs=%d: missing bucket %s/%s", jobs, slug, model)
+				}
+				name := fmt.Sprintf("jobs=%d %s", jobs, slug)
+				assertInt(t, name+" Requests", got.Requests, want.Requests)
+				assertInt(t, name+" InputTokens", got.InputTokens, want.InputTokens)
+				assertInt(t, name+" OutputTokens", got.OutputTokens, want.OutputTokens)
+				assertCost(t, name+" Cost", got.Cost, want.Cost)
+			}
+		}
+	}
+}
+
+// --- Model Aggregation ---
+
+func TestModelAggregation_MultipleModels(t *testing.T) {
+	lines := []string{
+		makeRecord("req_001", "claude-opus-4-6", ts(0, 10), 100, 50, 500, 200, 0),
+		makeRecord("req_002", "claude-sonnet-4-6", ts(0, 11), 80, 30, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.ModelUsage) != 2 {
+		t.Errorf("expected 2 models, got %d", len(data.ModelUsage))
+	}
+	opus := data.ModelUsage["claude-opus-4-6"]
+	if opus == nil || opus.Requests != 1 {
+		t.Error("expected 1 opus request")
+	}
+	sonnet := data.ModelUsage["claude-sonnet-4-6"]
+	if sonnet == nil || sonnet.Requests != 1 {
+		t.Error("expected 1 sonnet request")
+	}
+}
+
+// --- Project Filter ---
+
+func TestProjectFilter_MatchesSubstring(t *testing.T) {
+	base := setupProject(t, "my-cool-project", []string{
+		makeRecord("req_001", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	addProject(t, base, "other-project", []string{
+		makeRecord("req_002", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+
+	data, err := parseLogs(Options{BaseDir: base, ProjectFilter: "cool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 1 {
+		t.Errorf("expected 1 record matching 'cool', got %d", data.TotalRecords)
+	}
+}
+
+func TestProjectFilter_CaseInsensitive(t *testing.T) {
+	base := setupProject(t, "MyProject", []string{
+		makeRecord("req_001", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	data, err := parseLogs(Options{BaseDir: base, ProjectFilter: "myproject"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 1 {
+		t.Errorf("expected case-insensitive match, got %d records", data.TotalRecords)
+	}
+}
+
+func TestProjectFilter_UsesCwdOverSlug(t *testing.T) {
+	withCwd := func(line, cwd string) string {
+		return strings.Replace(line, `{"type":"assistant",`, `{"type":"assistant","cwd":"`+cwd+`",`, 1)
+	}
+	base := t.TempDir()
+	// Both directories slug to -home-bob-my-app.
+	addProject(t, base, "-home-bob-my-app", []string{
+		withCwd(makeRecord("req_dash", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0), "/home/bob/my-app"),
+	})
+	addProject(t, base, "-home-bob-my-app-", []string{
+		withCwd(makeRecord("req_nested", "claude-opus-4-6", ts(0, 11), 100, 50, 0, 0, 0), "/home/bob/my/app/"),
+	})
+
+	data, err := parseLogs(Options{BaseDir: base, ProjectFilter: "my/app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 1 || data.ProjectUsage["-home-bob-my-app-"] == nil {
+		t.Errorf("expected only the my/app project, got %d records", data.TotalRecords)
+	}
+	if got := data.ProjectPaths["-home-bob-my-app"]; got != "/home/bob/my-app" {
+		t.Errorf("ProjectPaths[-home-bob-my-app] = %q", got)
+	}
+}
+
+func TestProjectFilter_NoMatch(t *testing.T) {
+	base := setupProject(t, "test-project", []string{
+		makeRecord("req_001", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	data, err := parseLogs(Options{BaseDir: base, ProjectFilter: "nonexistent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 0 {
+		t.Errorf("expected 0 records with filter, got %d", data.TotalRecords)
+	}
+}
+
+// --- Days Filter ---
+
+func TestDaysFilter_ExcludesOldRecords(t *testing.T) {
+	lines := []string{
+		makeRecord("req_today", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		makeRecord("req_yesterday", "claude-opus-4-6", ts(1, 10), 100, 50, 0, 0, 0),
+		makeRecord("req_old", "claude-opus-4-6", ts(5, 10), 100, 50, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+
+	data, err := parseLogs(Options{BaseDir: base, Days: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 2 {
+		t.Errorf("expected 2 records (today + yesterday), got %d", data.TotalRecords)
+	}
+}
+
+func TestDaysFilter_OneDayMeansToday(t *testing.T) {
+	lines := []string{
+		makeRecord("req_today", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		makeRecord("req_yesterday", "claude-opus-4-6", ts(1, 10), 100, 50, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+
+	data, err := parseLogs(Options{BaseDir: base, Days: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 1 {
+		t.Errorf("expected 1 record (today only), got %d", data.TotalRecords)
+	}
+}
+
+func TestDaysFilter_BoundaryExactCutoff(t *testing.T) {
+	// A record at 00:00:00 on the cutoff day should be included
+	cutoffDay := localMidnight().AddDate(0, 0, -2)
+	atMidnight := cutoffDay.Format(time.RFC3339)
+	beforeMidnight := cutoffDay.Add(-1 * time.Second).Format(time.RFC3339)
+
+	lines := []string{
+		makeRecord("req_at_cutoff", "claude-opus-4-6", atMidnight, 100, 50, 0, 0, 0),
+		makeRecord("req_before_cutoff", "claude-opus-4-6", beforeMidnight, 100, 50, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+
+	// days=3: today, yesterday, 2 days ago
+	data, err := parseLogs(Options{BaseDir: base, Days: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 1 {
+		t.Errorf("expected 1 record (at cutoff included, before cutoff excluded), got %d", data.TotalRecords)
+	}
+}
+
+func TestDaysFilter_ZeroMeansAllTime(t *testing.T) {
+	lines := []string{
+		makeRecord("req_ancient", "claude-opus-4-6", "2020-01-01T00:00:00Z", 100, 50, 0, 0, 0),
+		makeRecord("req_today", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 2 {
+		t.Errorf("expected 2 records with days=0, got %d", data.TotalRecords)
+	}
+}
+
+func TestDaysFilter_ExcludesEmptyTimestamp(t *testing.T) {
+	lines := []string{
+		makeRecord("req_today", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		// Record without timestamp
+		`{"type":"assistant","requestId":"req_notime","message":{"model":"claude-opus-4-6","role":"assistant","usage":{"input_tokens":100,"output_tokens":50}}}`,
+	}
+	base := setupProject(t, "test-project", lines)
+
+	// With days filter, empty-timestamp records should be excluded
+	data, err := parseLogs(Options{BaseDir: base, Days: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 1 {
+		t.Errorf("expected 1 record (empty timestamp excluded with days filter), got %d", data.TotalRecords)
+	}
+
+	// Without days filter, empty-timestamp records should be included
+	data, err = parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 2 {
+		t.Errorf("expected 2 records (empty timestamp included without days filter), got %d", data.TotalRecords)
+	}
+}
+
+func TestDaysFilter_CountsCalendarDays(t *testing.T) {
+	// Build one record per day for the last 10 days
+	var lines []string
+	for i := 0; i < 10; i++ {
+		lines = append(lines, makeRecord(
+			fmt.Sprintf("req_%d", i), "claude-opus-4-6", ts(i, 12), 100, 50, 0, 0, 0,
+		))
+	}
+	base := setupProject(t, "test-project", lines)
+
+	data, err := parseLogs(Options{BaseDir: base, Days: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// days=7 should include today + 6 previous days = 7 records (days 0-6)
+	if data.TotalRecords != 7 {
+		t.Errorf("expected 7 records for days=7, got %d", data.TotalRecords)
+	}
+	// Verify the daily breakdown has exactly 7 dates
+	if len(data.DailyUsage) != 7 {
+		t.Errorf("expected 7 dates in daily breakdown, got %d", len(data.DailyUsage))
+	}
+}
+
+func TestTierAggregation(t *testing.T) {
+	withTier := func(line, tier string) string {
+		return strings.Replace(line, `"usage":{`, `"usage":{"service_tier":"`+tier+`",`, 1)
+	}
+	lines := []string{
+		withTier(makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 1_000_000, 0, 0, 0, 0), "standard"),
+		withTier(makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 1_000_000, 0, 0, 0, 0), "batch"),
+		withTier(makeRecord("req_3", "claude-opus-4-6", ts(0, 12), 1_000_000, 0, 0, 0, 0), "priority"),
+		makeRecord("req_4", "claude-opus-4-6", ts(0, 13), 1_000_000, 0, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{"standard": 5.0, "batch": 2.5, "priority": 5.0, "": 5.0}
+	if len(data.TierUsage) != len(want) {
+		t.Errorf("got %d tiers, want %d", len(data.TierUsage), len(want))
+	}
+	for tier, cost := range want {
+		b := data.TierUsage[tier]
+		if b == nil || b.Requests != 1 {
+			t.Errorf("tier %q = %+v, want 1 request", tier, b)
+			continue
+		}
+		assertCost(t, "tier "+tier, b.Cost, cost)
+	}
+	assertCost(t, "total", data.Totals().Cost, 17.5)
+}
+
+func TestLongContextAggregation(t *testing.T) {
+	lines := []string{
+		makeRecord("req_short", "claude-sonnet-4-6", ts(0, 10), 10_000, 1_000, 100_000, 0, 0),
+		makeRecord("req_long", "claude-sonnet-4-6", ts(0, 11), 10_000, 1_000, 250_000, 0, 0),
+		makeRecord("req_opus", "claude-opus-4-6", ts(0, 12), 10_000, 1_000, 250_000, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.LongContext.Requests != 1 {
+		t.Errorf("LongContext.Requests = %d, want 1", data.LongContext.Requests)
+	}
+	// 10K * $6 + 1K * $22.50 + 250K * $0.60
+	assertCost(t, "LongContext.Cost", data.LongContext.Cost, 0.2325)
+}
+
+func TestServerToolAggregation(t *testing.T) {
+	withServerTools := func(line string, searches, fetches int) string {
+		return strings.Replace(line, `"usage":{`,
+			fmt.Sprintf(`"usage":{"server_tool_use":{"web_search_requests":%d,"web_fetch_requests":%d},`, searches, fetches), 1)
+	}
+	lines := []string{
+		withServerTools(makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 1000, 100, 0, 0, 0), 2, 1),
+		withServerTools(makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 1000, 100, 0, 0, 0), 3, 0),
+		makeRecord("req_3", "claude-haiku-4-5-20251001", ts(0, 12), 1000, 100, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opus := data.ModelUsage["claude-opus-4-6"]
+	if opus.WebSearches != 5 || opus.WebFetches != 1 {
+		t.Errorf("opus server tools = %d searches, %d fetches; want 5, 1", opus.WebSearches, opus.WebFetches)
+	}
+	totals := data.Totals()
+	if totals.Searches != 5 || totals.Fetches != 1 {
+		t.Errorf("totals = %d searches, %d fetches; want 5, 1", totals.Searches, totals.Fetches)
+	}
+	// 2 * (1K * $5 + 100 * $25) + 5 searches * $0.01
+	assertCost(t, "opus cost", opus.Cost, 0.065)
+}
+
+func TestSinceUntilFilter(t *testing.T) {
+	lines := []string{
+		makeRecord("req_before", "claude-opus-4-6", "2026-08-31T23:59:59Z", 100, 50, 0, 0, 0),
+		makeRecord("req_first", "claude-opus-4-6", "2026-09-01T00:00:00Z", 100, 50, 0, 0, 0),
+		makeRecord("req_last", "claude-opus-4-6", "2026-09-30T23:59:59Z", 100, 50, 0, 0, 0),
+		makeRecord("req_after", "claude-opus-4-6", "2026-10-01T00:00:00Z", 100, 50, 0, 0, 0),
+		`{"type":"assistant","requestId":"req_notime","message":{"model":"claude-opus-4-6","role":"assistant","usage":{"input_tokens":100,"output_tokens":50}}}`,
+	}
+	base := setupProject(t, "test-project", lines)
+
+	since := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		opts  Options
+		count int
+	}{
+		{"since only", Options{BaseDir: base, Since: since}, 3},
+		{"until only", Options{BaseDir: base, Until: until}, 3},
+		{"both", Options{BaseDir: base, Since: since, Until: until}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parseLogs(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data.TotalRecords != tt.count {
+				t.Errorf("got %d records, want %d", data.TotalRecords, tt.count)
+			}
+			if !data.Since.Equal(tt.opts.Since) || !data.Until.Equal(tt.opts.Until) {
+				t.Errorf("range = %v..%v, want %v..%v", data.Since, data.Until, tt.opts.Since, tt.opts.Until)
+			}
+		})
+	}
+}
+
+func TestSinceFilter_CombinesWithDays(t *testing.T) {
+	lines := []string{
+		makeRecord("req_today", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		makeRecord("req_3days", "claude-opus-4-6", ts(3, 10), 100, 50, 0, 0, 0),
+		makeRecord("req_10days", "claude-opus-4-6", ts(10, 10), 100, 50, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+
+	// The later of the two lower bounds wins.
+	data, err := parseLogs(Options{BaseDir: base, Days: 30, Since: localMidnight().AddDate(0, 0, -5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 2 {
+		t.Errorf("expected 2 records, got %d", data.TotalRecords)
+	}
+	data, err = parseLogs(Options{BaseDir: base, Days: 2, Since: localMidnight().AddDate(0, 0, -5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 1 {
+		t.Errorf("expected 1 record, got %d", data.TotalRecords)
+	}
+}
+
+func TestDailyUsage_TimeZoneAndDayStart(t *testing.T) {
+	// 23:30 UTC on the 18th is 08:30 on the 19th in Tokyo.
+	lines := []string{
+		makeRecord("req_late", "claude-opus-4-6", "2026-02-18T23:30:00Z", 100, 50, 0, 0, 0),
+		makeRecord("req_noon", "claude-opus-4-6", "2026-02-19T12:00:00Z", 100, 50, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		days map[string]int
+	}{
+		{"utc", Options{BaseDir: base, Location: time.UTC}, map[string]int{"2026-02-18": 1, "2026-02-19": 1}},
+		{"tokyo", Options{BaseDir: base, Location: tokyo}, map[string]int{"2026-02-19": 2}},
+		{"utc day starts 00:30", Options{BaseDir: base, Location: time.UTC, DayStart: 30 * time.Minute}, map[string]int{"2026-02-18": 1, "2026-02-19": 1}},
+		{"utc day starts 12:30", Options{BaseDir: base, Location: time.UTC, DayStart: 12*time.Hour + 30*time.Minute}, map[string]int{"2026-02-18": 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parseLogs(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data.DailyUsage) != len(tt.days) {
+				t.Errorf("got %d days, want %d", len(data.DailyUsage), len(tt.days))
+			}
+			for day, reqs := range tt.days {
+				if b := data.DailyUsage[day]["claude-opus-4-6"]; b == nil || b.Requests != reqs {
+					t.Errorf("day %s = %+v, want %d requests", day, b, reqs)
+				}
+			}
+		})
+	}
+
+	data, err := parseLogs(Options{BaseDir: base, Location: tokyo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := data.Heatmap[time.Thursday][8]; b.Requests != 1 {
+		t.Errorf("heatmap Thursday 08:00 Tokyo = %d requests, want 1", b.Requests)
+	}
+}
+
+func TestDaysFilter_HonoursDayStart(t *testing.T) {
+	// With the day starting at 06:00, a record at 03:00 today still belongs
+	// to yesterday, which -days 1 excludes.
+	now := time.Now()
+	todayStart := Clock{Location: time.Local, DayStart: 6 * time.Hour}.startOfDay(now)
+	lines := []string{
+		makeRecord("req_before", "claude-opus-4-6", todayStart.Add(-3*time.Hour).Format(time.RFC3339), 100, 50, 0, 0, 0),
+		makeRecord("req_after", "claude-opus-4-6", todayStart.Add(time.Minute).Format(time.RFC3339), 100, 50, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+
+	data, err := parseLogs(Options{BaseDir: base, Days: 1, DayStart: 6 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 1 {
+		t.Errorf("expected 1 record in today's report day, got %d", data.TotalRecords)
+	}
+}
+
+func TestSinceFilter_SkipsOldFilesByModTime(t *testing.T) {
+	lines := []string{makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0)}
+	base := setupProject(t, "test-project", lines)
+	old := localMidnight().AddDate(0, 0, -10)
+	path := filepath.Join(base, "projects", "test-project", "session.jsonl")
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := parseLogs(Options{BaseDir: base, Since: localMidnight().AddDate(0, 0, -5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalFiles != 0 {
+		t.Errorf("expected file modified before -since to be skipped, parsed %d", data.TotalFiles)
+	}
+
+	// An upper bound alone must not skip files by mtime.
+	data, err = parseLogs(Options{BaseDir: base, Until: localMidnight().AddDate(0, 0, -20)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalFiles != 1 {
+		t.Errorf("expected -until not to skip files, parsed %d", data.TotalFiles)
+	}
+}
+
+// --- Daily and Project Aggregation ---
+
+func TestDailyAggregation(t *testing.T) {
+	lines := []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		makeRecord("req_2", "claude-opus-4-6", ts(0, 14), 200, 100, 0, 0, 0),
+		makeRecord("req_3", "claude-opus-4-6", ts(1, 10), 300, 150, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	today := dateStr(0)
+	yesterday := dateStr(1)
+
+	todayBucket := data.DailyUsage[today]["claude-opus-4-6"]
+	if todayBucket == nil {
+		t.Fatalf("no bucket for today %s", today)
+	}
+	if todayBucket.Requests != 2 {
+		t.Errorf("expected 2 requests today, got %d", todayBucket.Requests)
+	}
+	if todayBucket.InputTokens != 300 {
+		t.Errorf("expected 300 input tokens today, got %d", todayBucket.InputTokens)
+	}
+
+	yesterdayBucket := data.DailyUsage[yesterday]["claude-opus-4-6"]
+	if yesterdayBucket == nil {
+		t.Fatalf("no bucket for yesterday %s", yesterday)
+	}
+	if yesterdayBucket.Requests != 1 {
+		t.Errorf("expected 1 request yesterday, got %d", yesterdayBucket.Requests)
+	}
+}
+
+func TestProjectAggregation(t *testing.T) {
+	base := setupProject(t, "project-alpha", []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		makeRecord("req_2", "claude-sonnet-4-6", ts(0, 11), 80, 30, 0, 0, 0),
+	})
+	addProject(t, base, "project-beta", []string{
+		makeRecord("req_3", "claude-opus-4-6", ts(0, 10), 200, 100, 0, 0, 0),
+	})
+
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alpha := data.ProjectUsage["project-alpha"]
+	if alpha == nil {
+		t.Fatal("expected project-alpha in results")
+	}
+	if len(alpha) != 2 {
+		t.Errorf("expected 2 models in project-alpha, got %d", len(alpha))
+	}
+
+	beta := data.ProjectUsage["project-beta"]
+	if beta == nil {
+		t.Fatal("expected project-beta in results")
+	}
+	if beta["claude-opus-4-6"].InputTokens != 200 {
+		t.Errorf("expected 200 input for project-beta opus, got %d", beta["claude-opus-4-6"].InputTokens)
+	}
+}
+
+func TestHeatmapAggregation(t *testing.T) {
+	lines := []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		makeRecord("req_2", "claude-opus-4-6", ts(0, 10), 200, 100, 0, 0, 0),
+		makeRecord("req_3", "claude-opus-4-6", ts(1, 22), 300, 150, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	today := localMidnight().Weekday()
+	yesterday := localMidnight().AddDate(0, 0, -1).Weekday()
+	assertInt(t, "today 10:00 requests", data.Heatmap[today][10].Requests, 2)
+	assertInt(t, "yesterday 22:00 requests", data.Heatmap[yesterday][22].Requests, 1)
+
+	var cells int
+	for wd := range data.Heatmap {
+		for h := range data.Heatmap[wd] {
+			cells += data.Heatmap[wd][h].Requests
+		}
+	}
+	assertInt(t, "heatmap total requests", cells, 3)
+}
+
+func TestHeatmap_HonoursDayStart(t *testing.T) {
+	// With the day starting at 04:00, 02:00 belongs to the previous day's
+	// row but keeps its clock hour.
+	lines := []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(1, 2), 100, 50, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+	data, err := parseLogs(Options{BaseDir: base, DayStart: 4 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dayBefore := localMidnight().AddDate(0, 0, -2).Weekday()
+	assertInt(t, "day before 02:00 requests", data.Heatmap[dayBefore][2].Requests, 1)
+}
+
+// --- Cache Token Handling ---
+
+func TestCacheTokenAggregation(t *testing.T) {
+	lines := []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 500, 200, 100),
+		makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 100, 50, 300, 0, 150),
+	}
+	base := setupProject(t, "test-project", lines)
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opus := data.ModelUsage["claude-opus-4-6"]
+	if opus.CacheRead != 800 {
+		t.Errorf("expected cache_read=800, got %d", opus.CacheRead)
+	}
+	if opus.CacheWrite5m != 200 {
+		t.Errorf("expected cache_write_5m=200, got %d", opus.CacheWrite5m)
+	}
+	if opus.CacheWrite1h != 250 {
+		t.Errorf("expected cache_write_1h=250, got %d", opus.CacheWrite1h)
+	}
+	if opus.TotalCacheWrite() != 450 {
+		t.Errorf("expected total_cache_write=450, got %d", opus.TotalCacheWrite())
+	}
+}
+
+func TestCacheTokenFallback_FlatField(t *testing.T) {
+	// When cache_creation is nil, cache_creation_input_tokens falls back to 5m
+	line := `{"type":"assistant","requestId":"req_flat","timestamp":` +
+		fmt.Sprintf("%q", ts(0, 10)) +
+		`,"message":{"model":"claude-opus-4-6","role":"assistant","usage":{"input_tokens":100,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":500}}}`
+
+	base := setupProject(t, "test-project", []string{line})
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opus := data.ModelUsage["claude-opus-4-6"]
+	if opus.CacheWrite5m != 500 {
+		t.Errorf("expected flat cache_creation to become cache_write_5m=500, got %d", opus.CacheWrite5m)
+	}
+	if opus.CacheWrite1h != 0 {
+		t.Errorf("expected cache_write_1h=0, got %d", opus.CacheWrite1h)
+	}
+}
+
+// --- Edge Cases ---
+
+func TestSkipsNonAssistantRecords(t *testing.T) {
+	lines := []string{
+		makeUserRecord(ts(0, 10)),
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 1 {
+		t.Errorf("expected 1 record (user record skipped), got %d", data.TotalRecords)
+	}
+}
+
+func TestSkipsMalformedJSON(t *testing.T) {
+	lines := []string{
+		`{"type":"assistant", this is not valid json}`,
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	}
+	base := setupProject(t, "test-project", lines)
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 1 {
+		t.Errorf("expected 1 valid record, got %d", data.TotalRecords)
+	}
+	if data.ParseErrors != 1 {
+		t.Errorf("expected 1 parse error, got %d", data.ParseErrors)
+	}
+}
+
+func TestEmptyProject(t *testing.T) {
+	base := setupProject(t, "empty-project", []string{})
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 0 {
+		t.Errorf("expected 0 records, got %d", data.TotalRecords)
+	}
+}
+
+func TestSkipsSyntheticErrorEntries(t *testing.T) {
+	lines := []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		`{"type":"assistant","requestId":"req_err","timestamp":"` + ts(0, 11) + `","message":{"model":"<synthetic>","role":"assistant","content":[{"type":"text","text":"Claude AI usage limit reached|1755295200"}],"usage":{"input_tokens":0,"output_tokens":0,"cache_creation_input_tokens":0,"cache_read_input_tokens":0}},"isApiErrorMessage":true}`,
+	}
+	base := setupProject(t, "test-project", lines)
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.TotalRecords != 1 {
+		t.Errorf("expected 1 record (synthetic skipped), got %d", data.TotalRecords)
+	}
+	if _, ok := data.ModelUsage["<synthetic>"]; ok {
+		t.Error("synthetic model should not appear in results")
+	}
+}
+
+func TestNoProjectsDir(t *testing.T) {
+	base := t.TempDir()
+	_, err := parseLogs(Options{BaseDir: base})
+	if err == nil {
+		t.Error("expected error when projects dir doesn't exist")
+	}
+}
+
+// --- Sessions ---
+
+func TestSourceForPath(t *testing.T) {
+	tests := []struct {
+		rel  string
+		want fileSource
+	}{
+		{"proj/abc.jsonl", fileSource{Project: "proj", Session: "abc"}},
+		{"proj/abc/subagents/agent-1.jsonl", fileSource{Project: "proj", Session: "abc", Subagent: true, Agent: "1"}},
+		{"proj/notes/other.jsonl", fileSource{Project: "proj", Session: "other"}},
+	}
+	for _, tt := range tests {
+		if got := sourceForPath(filepath.FromSlash(tt.rel)); got != tt.want {
+			t.Errorf("sourceForPath(%q) = %+v, want %+v", tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestSessionAggregation_SubagentRollsUp(t *testing.T) {
+	base := t.TempDir()
+	projDir := filepath.Join(base, "projects", "test-project")
+	subDir := filepath.Join(projDir, "sess-1", "subagents")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	mainContent := makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0) + "\n"
+	// Subagent records may carry their own sessionId; the path decides.
+	subContent := strings.Replace(makeRecord("req_2", "claude-haiku-4-5-20251001", ts(0, 11), 100, 50, 0, 0, 0),
+		`"type":"assistant"`, `"type":"assistant","sessionId":"agent-session"`, 1) + "\n"
+	otherContent := makeRecord("req_3", "claude-opus-4-6", ts(0, 12), 100, 50, 0, 0, 0) + "\n"
+	for path, content := range map[string]string{
+		filepath.Join(projDir, "sess-1.jsonl"):  mainContent,
+		filepath.Join(subDir, "agent-a1.jsonl"): subContent,
+		filepath.Join(projDir, "sess-2.jsonl"):  otherContent,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.SessionUsage) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(data.SessionUsage))
+	}
+	reqs, _ := data.SessionUsage["sess-1"].Totals()
+	assertInt(t, "sess-1 requests", reqs, 2)
+	reqs, _ = data.SessionUsage["sess-2"].Totals()
+	assertInt(t, "sess-2 requests", reqs, 1)
+}
+
+// --- Branches ---
+
+func withBranch(line, branch string) string {
+	return strings.Replace(line, `"type":"assistant"`, fmt.Sprintf(`"type":"assistant","gitBranch":%q`, branch), 1)
+}
+
+func TestBranchAggregation_PerProject(t *testing.T) {
+	base := setupProject(t, "project-a", []string{
+		withBranch(makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0), "main"),
+		withBranch(makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 100, 50, 0, 0, 0), "feature/login"),
+		makeRecord("req_3", "claude-opus-4-6", ts(0, 12), 100, 50, 0, 0, 0),
+	})
+	addProject(t, base, "project-b", []string{
+		withBranch(makeRecord("req_4", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0), "main"),
+	})
+
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := data.BranchUsage["project-a"]
+	if len(a) != 3 {
+		t.Fatalf("project-a has %d branches, want 3 (main, feature/login, none)", len(a))
+	}
+	assertInt(t, "project-a main", a["main"].Requests, 1)
+	assertInt(t, "project-a no branch", a[""].Requests, 1)
+	assertInt(t, "project-b main", data.BranchUsage["project-b"]["main"].Requests, 1)
+}
+
+func TestBranchFilter_Glob(t *testing.T) {
+	base := setupProject(t, "test-project", []string{
+		withBranch(makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0), "main"),
+		withBranch(makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 100, 50, 0, 0, 0), "feature/login"),
+		withBranch(makeRecord("req_3", "claude-opus-4-6", ts(0, 12), 100, 50, 0, 0, 0), "feature/signup"),
+	})
+
+	tests := []struct {
+		filter string
+		want   int
+	}{
+		{"main", 1},
+		{"feature/*", 2},
+		{"feature", 0},
+	}
+	for _, tt := range tests {
+		data, err := parseLogs(Options{BaseDir: base, BranchFilter: tt.filter})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data.TotalRecords != tt.want {
+			t.Errorf("branch filter %q: got %d records, want %d", tt.filter, data.TotalRecords, tt.want)
+		}
+	}
+}
+
+// --- Versions ---
+
+func TestVersionAggregation(t *testing.T) {
+	withVersion := func(line, version string) string {
+		return strings.Replace(line, `"type":"assistant"`, fmt.Sprintf(`"type":"assistant","version":%q`, version), 1)
+	}
+	base := setupProject(t, "test-project", []string{
+		withVersion(makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 900, 0, 0), "2.1.47"),
+		withVersion(makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 100, 50, 0, 0, 0), "2.1.47"),
+		withVersion(makeRecord("req_3", "claude-opus-4-6", ts(0, 12), 100, 50, 0, 0, 0), "2.1.50"),
+		makeRecord("req_4", "claude-opus-4-6", ts(0, 13), 100, 50, 0, 0, 0),
+	})
+	data, err := parseLogs(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.VersionUsage) != 3 {
+		t.Fatalf("expected 3 versions (incl. unknown), got %d", len(data.VersionUsage))
+	}
+	v47 := data.VersionUsage["2.1.47"]
+	assertInt(t, "2.1.47 requests", v47.Requests, 2)
+	assertCost(t, "2.1.47 cache hit ratio", v47.CacheHitRatio(), 0.818182)
+	assertInt(t, "unknown requests", data.VersionUsage[""].Requests, 1)
+}
+
+func TestTotals(t *testing.T) {
+	result := &ParseResult{
+		ModelUsage: map[string]*Bucket{
+			"claude-opus-4-6": {
+				InputTokens: 1000, OutputTokens: 500,
+				CacheRead: 200, CacheWrite5m: 100, CacheWrite1h: 50,
+				Cost: 1.5, Requests: 2,
+			},
+			"claude-haiku-4-5-20251001": {
+				InputTokens: 800, OutputTokens: 300,
+				CacheRead: 100, CacheWrite5m: 60, CacheWrite1h: 40,
+				Cost: 0.5, Requests: 3,
+			},
+		},
+	}
+
+	totals := result.Totals()
+	assertInt(t, "totals.Input", totals.Input, 1800)
+	assertInt(t, "totals.Output", totals.Output, 800)
+	assertInt(t, "totals.CacheR", totals.CacheR, 300)
+	assertInt(t, "totals.CacheW5m", totals.CacheW5m, 160)
+	assertInt(t, "totals.CacheW1h", totals.CacheW1h, 90)
+	assertInt(t, "totals.CacheW", totals.CacheW, 250)
+	assertInt(t, "totals.Requests", totals.Requests, 5)
+	assertCost(t, "totals.Cost", totals.Cost, 2.0)
+}
+
+func TestDateRange(t *testing.T) {
+	tests := []struct {
+		name             string
+		dates            []string
+		wantFrom, wantTo string
+	}{
+		{
+			name:     "multiple dates",
+			dates:    []string{"2026-02-18", "2026-02-20", "2026-02-19"},
+			wantFrom: "2026-02-18", wantTo: "2026-02-20",
+		},
+		{
+			name:     "single date",
+			dates:    []string{"2026-02-19"},
+			wantFrom: "2026-02-19", wantTo: "2026-02-19",
+		},
+		{
+			name:     "unknown dates excluded",
+			dates:    []string{"unknown", "2026-02-18", "2026-02-20"},
+			wantFrom: "2026-02-18", wantTo: "2026-02-20",
+		},
+		{
+			name:     "only unknown",
+			dates:    []string{"unknown"},
+			wantFrom: "", wantTo: "",
+		},
+		{
+			name:     "empty",
+			dates:    nil,
+			wantFrom: "", wantTo: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ParseResult{DailyUsage: make(map[string]map[string]*Bucket)}
+			for _, d := range tt.dates {
+				r.DailyUsage[d] = map[string]*Bucket{"model": {}}
+			}
+			from, to := r.DateRange()
+			if from != tt.wantFrom {
+				t.Errorf("from = %q, want %q", from, tt.wantFrom)
+			}
+			if to != tt.wantTo {
+				t.Errorf("to = %q, want %q", to, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestTotalCacheWrite(t *testing.T) {
+	b := &Bucket{CacheWrite5m: 100, CacheWrite1h: 200}
+	if got := b.TotalCacheWrite(); got != 300 {
+		t.Errorf("TotalCacheWrite() = %d, want 300", got)
+	}
+}
+
+func TestCacheHitRatio(t *testing.T) {
+	b := &Bucket{InputTokens: 100, CacheRead: 600, CacheWrite5m: 200, CacheWrite1h: 100}
+	assertCost(t, "CacheHitRatio", b.CacheHitRatio(), 0.6)
+	assertCost(t, "empty CacheHitRatio", (&Bucket{}).CacheHitRatio(), 0)
+}
@@ -0,0 +1,86 @@
+package usage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Periods the daily buckets can be rolled up into.
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// WeekOptions controls how days are grouped into weeks. With ISO set, weeks
+// start on Monday and are labelled by ISO 8601 week number (2026-W08);
+// otherwise they start on Start and are labelled by their first day.
+type WeekOptions struct {
+	Start time.Weekday
+	ISO   bool
+}
+
+// ParsePeriod parses a -period value: day, week or month, or the -daily
+// style spelling of each. It returns one of the Period constants.
+func ParsePeriod(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "day", "daily":
+		return PeriodDay, nil
+	case "week", "weekly":
+		return PeriodWeek, nil
+	case "month", "monthly":
+		return PeriodMonth, nil
+	}
+	return "", fmt.Errorf("unknown period %q (want day, week or month)", s)
+}
+
+// ParseWeekday parses a weekday name, full or abbreviated to three letters,
+// in any case.
+func ParseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(s)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", s)
+}
+
+// periodKey maps a daily bucket key (2006-01-02) to the period containing it.
+// Keys that are not dates, such as "unknown", pass through unchanged.
+func periodKey(date, period string, week WeekOptions) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	switch period {
+	case PeriodMonth:
+		return t.Format("2006-01")
+	case PeriodWeek:
+		if week.ISO {
+			year, wk := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, wk)
+		}
+		offset := (int(t.Weekday()) - int(week.Start) + 7) % 7
+		return t.AddDate(0, 0, -offset).Format("2006-01-02")
+	}
+	return date
+}
+
+// PeriodUsage rolls DailyUsage up into weeks or months, keyed by period
+// label and then model.
+func (r *ParseResult) PeriodUsage(period string, week WeekOptions) map[string]map[string]*Bucket {
+	if period == PeriodDay {
+		return r.DailyUsage
+	}
+	usage := make(map[string]map[string]*Bucket)
+	for date, models := range r.DailyUsage {
+		key := periodKey(date, period, week)
+		for model, b := range models {
+			getOrCreateNestedBucket(usage, key, model).add(b)
+		}
+	}
+	return usage
+}
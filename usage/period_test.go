@@ -0,0 +1,91 @@
+package usage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodKey(t *testing.T) {
+	tests := []struct {
+		date   string
+		period string
+		week   WeekOptions
+		want   string
+	}{
+		{"2026-02-19", PeriodDay, WeekOptions{}, "2026-02-19"},
+		{"2026-02-19", PeriodMonth, WeekOptions{}, "2026-02"},
+		// 2026-02-19 is a Thursday.
+		{"2026-02-19", PeriodWeek, WeekOptions{Start: time.Monday}, "2026-02-16"},
+		{"2026-02-19", PeriodWeek, WeekOptions{Start: time.Sunday}, "2026-02-15"},
+		{"2026-02-15", PeriodWeek, WeekOptions{Start: time.Sunday}, "2026-02-15"},
+		{"2026-02-15", PeriodWeek, WeekOptions{Start: time.Monday}, "2026-02-09"},
+		{"2026-02-19", PeriodWeek, WeekOptions{ISO: true}, "2026-W08"},
+		// ISO years can differ from the calendar year at the boundary.
+		{"2027-01-01", PeriodWeek, WeekOptions{ISO: true}, "2026-W53"},
+		{"2026-01-01", PeriodWeek, WeekOptions{Start: time.Monday}, "2025-12-29"},
+		{"unknown", PeriodWeek, WeekOptions{}, "unknown"},
+	}
+	for _, tt := range tests {
+		got := periodKey(tt.date, tt.period, tt.week)
+		if got != tt.want {
+			t.Errorf("periodKey(%q, %q, %+v) = %q, want %q", tt.date, tt.period, tt.week, got, tt.want)
+		}
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	for in, want := range map[string]string{"day": PeriodDay, "Weekly": PeriodWeek, "month": PeriodMonth} {
+		got, err := ParsePeriod(in)
+		if err != nil || got != want {
+			t.Errorf("ParsePeriod(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParsePeriod("year"); err == nil {
+		t.Error("expected error for unknown period")
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	for in, want := range map[string]time.Weekday{"mon": time.Monday, "Sunday": time.Sunday, "sat": time.Saturday} {
+		got, err := ParseWeekday(in)
+		if err != nil || got != want {
+			t.Errorf("ParseWeekday(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseWeekday("someday"); err == nil {
+		t.Error("expected error for unknown weekday")
+	}
+}
+
+func TestPeriodUsage(t *testing.T) {
+	data := &ParseResult{DailyUsage: map[string]map[string]*Bucket{
+		"2026-02-16": {"claude-opus-4-6": {Requests: 1, Cost: 1.0}},
+		"2026-02-19": {"claude-opus-4-6": {Requests: 2, Cost: 2.0}, "claude-haiku-4-5": {Requests: 1, Cost: 0.5}},
+		"2026-03-02": {"claude-opus-4-6": {Requests: 4, Cost: 4.0}},
+	}}
+
+	weekly := data.PeriodUsage(PeriodWeek, WeekOptions{Start: time.Monday})
+	if len(weekly) != 2 {
+		t.Fatalf("got %d weeks, want 2", len(weekly))
+	}
+	opus := weekly["2026-02-16"]["claude-opus-4-6"]
+	if opus == nil || opus.Requests != 3 || opus.Cost != 3.0 {
+		t.Errorf("week 2026-02-16 opus = %+v, want 3 requests, $3", opus)
+	}
+	if b := weekly["2026-02-16"]["claude-haiku-4-5"]; b == nil || b.Requests != 1 {
+		t.Errorf("week 2026-02-16 haiku = %+v, want 1 request", b)
+	}
+
+	monthly := data.PeriodUsage(PeriodMonth, WeekOptions{})
+	if b := monthly["2026-02"]["claude-opus-4-6"]; b == nil || b.Requests != 3 {
+		t.Errorf("month 2026-02 opus = %+v, want 3 requests", b)
+	}
+	if b := monthly["2026-03"]["claude-opus-4-6"]; b == nil || b.Requests != 4 {
+		t.Errorf("month 2026-03 opus = %+v, want 4 requests", b)
+	}
+
+	// Rolling up must not mutate the daily buckets.
+	if b := data.DailyUsage["2026-02-16"]["claude-opus-4-6"]; b.Requests != 1 {
+		t.Errorf("daily bucket mutated: %+v", b)
+	}
+}
@@ -0,0 +1,266 @@
+package usage
+
+import (
+	"sort"
+	"strings"
+)
+
+// ModelPricing holds a model's rates in dollars per million tokens. Cache
+// rates are derived from Input.
+type ModelPricing struct {
+	Input  float64
+	Output float64
+	Tiers  []PriceTier // ascending by Threshold
+	// TierMultipliers scale every token rate by the request's service tier
+	// (usage.service_tier). Nil uses standardTierMultipliers; tiers missing
+	// from the map are billed at 1x.
+	TierMultipliers map[string]float64
+}
+
+// PriceTier replaces a model's base rates for requests whose total input
+// (input + cache read + cache write tokens) exceeds Threshold.
+type PriceTier struct {
+	Threshold int
+	Input     float64
+	Output    float64
+}
+
+// LongContextThreshold is where long-context (1M window) pricing starts.
+const LongContextThreshold = 200_000
+
+// sonnetLongContext is the premium for Sonnet requests past 200K input tokens.
+var sonnetLongContext = []PriceTier{{Threshold: LongContextThreshold, Input: 6.00, Output: 22.50}}
+
+// CacheWrite5m is the rate for 5-minute cache writes: 1.25x input.
+func (p ModelPricing) CacheWrite5m() float64 { return p.Input * 1.25 }
+
+// CacheWrite1h is the rate for 1-hour cache writes: 2x input.
+func (p ModelPricing) CacheWrite1h() float64 { return p.Input * 2.0 }
+
+// CacheRead is the rate for cache reads: 0.1x input.
+func (p ModelPricing) CacheRead() float64 { return p.Input * 0.1 }
+
+// Service tiers reported in usage.service_tier.
+const (
+	tierStandard = "standard"
+	tierPriority = "priority"
+	tierBatch    = "batch"
+)
+
+// standardTierMultipliers are the TierMultipliers of every model that
+// doesn't set its own. Priority Tier capacity is committed up front; its
+// per-token usage is billed at standard rates, so it carries no multiplier
+// here. Unknown or missing tiers are billed as standard.
+var standardTierMultipliers = map[string]float64{
+	tierStandard: 1.0,
+	tierPriority: 1.0,
+	tierBatch:    0.5,
+}
+
+// TierMultiplier returns the factor every token rate is scaled by for
+// requests served on the given service tier.
+func (p ModelPricing) TierMultiplier(tier string) float64 {
+	multipliers := p.TierMultipliers
+	if multipliers == nil {
+		multipliers = standardTierMultipliers
+	}
+	if m, ok := multipliers[tier]; ok {
+		return m
+	}
+	return 1
+}
+
+// ForInput returns the base rates that apply to a request with the given
+// total input tokens, and whether a threshold tier replaced them.
+func (p ModelPricing) ForInput(tokens int) (ModelPricing, bool) {
+	rates := ModelPricing{Input: p.Input, Output: p.Output}
+	tiered := false
+	for _, t := range p.Tiers {
+		if tokens > t.Threshold {
+			rates.Input, rates.Output = t.Input, t.Output
+			tiered = true
+		}
+	}
+	return rates, tiered
+}
+
+// Source: https://platform.claude.com/docs/en/about-claude/pricing
+var pricingTable = map[string]ModelPricing{
+	"claude-opus-4-6":            {Input: 5.00, Output: 25.00},
+	"claude-opus-4-5-20251101":   {Input: 5.00, Output: 25.00},
+	"claude-opus-4-1-20250414":   {Input: 15.00, Output: 75.00},
+	"claude-sonnet-4-6":          {Input: 3.00, Output: 15.00, Tiers: sonnetLongContext},
+	"claude-sonnet-4-5-20250929": {Input: 3.00, Output: 15.00, Tiers: sonnetLongContext},
+	"claude-sonnet-4-20250514":   {Input: 3.00, Output: 15.00, Tiers: sonnetLongContext},
+	"claude-haiku-4-5-20251001":  {Input: 1.00, Output: 5.00},
+	"claude-haiku-3-5-20241022":  {Input: 0.80, Output: 4.00},
+}
+
+// Sorted longest-first at init for correct prefix matching.
+var familyPrefixes = []struct {
+	Prefix string
+	Key    string
+}{
+	{"claude-opus-4-6", "claude-opus-4-6"},
+	{"claude-opus-4-5", "claude-opus-4-5-20251101"},
+	{"claude-opus-4-1", "claude-opus-4-1-20250414"},
+	{"claude-opus-4", "claude-opus-4-1-20250414"},
+	{"claude-sonnet-4-6", "claude-sonnet-4-6"},
+	{"claude-sonnet-4-5", "claude-sonnet-4-5-20250929"},
+	{"claude-sonnet-4", "claude-sonnet-4-20250514"},
+	{"claude-sonnet-3", "claude-sonnet-4-5-20250929"},
+	{"claude-haiku-4-5", "claude-haiku-4-5-20251001"},
+	{"claude-haiku-3-5", "claude-haiku-3-5-20241022"},
+	{"claude-haiku-3", "claude-haiku-3-5-20241022"},
+}
+
+func init() {
+	sort.Slice(familyPrefixes, func(i, j int) bool {
+		return len(familyPrefixes[i].Prefix) > len(familyPrefixes[j].Prefix)
+	})
+}
+
+// DefaultModel prices models that match no entry or family prefix.
+const DefaultModel = "claude-sonnet-4-6"
+
+var defaultPricing = pricingTable[DefaultModel]
+
+// Server tools billed per use on top of tokens, in dollars per 1000 uses.
+var serverToolPricing = map[string]float64{
+	"web_search": 10.00,
+	"web_fetch":  0, // only the fetched content's tokens are billed
+}
+
+// ResolvePricing is LookupPricing with a fallback: models it doesn't know
+// are priced at DefaultModel's rates.
+func ResolvePricing(model string) ModelPricing {
+	if p, ok := LookupPricing(model); ok {
+		return p
+	}
+	return defaultPricing
+}
+
+// LookupPricing finds a model's pricing by exact id or family prefix.
+func LookupPricing(model string) (ModelPricing, bool) {
+	if p, ok := pricingTable[model]; ok {
+		return p, true
+	}
+	for _, fp := range familyPrefixes {
+		if strings.HasPrefix(model, fp.Prefix) {
+			return pricingTable[fp.Key], true
+		}
+	}
+	return ModelPricing{}, false
+}
+
+// CacheCreation splits cache writes by lifetime.
+type CacheCreation struct {
+	Ephemeral5mInputTokens int `json:"ephemeral_5m_input_tokens"`
+	Ephemeral1hInputTokens int `json:"ephemeral_1h_input_tokens"`
+}
+
+// Usage is the usage block of an assistant message, as logged.
+type Usage struct {
+	InputTokens              int            `json:"input_tokens"`
+	OutputTokens             int            `json:"output_tokens"`
+	CacheReadInputTokens     int            `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int            `json:"cache_creation_input_tokens"`
+	CacheCreation            *CacheCreation `json:"cache_creation,omitempty"`
+	ServiceTier              string         `json:"service_tier,omitempty"`
+	ServerToolUse            *ServerToolUse `json:"server_tool_use,omitempty"`
+}
+
+// ServerToolUse counts server-side tool requests, billed per use.
+type ServerToolUse struct {
+	WebSearchRequests int `json:"web_search_requests"`
+	WebFetchRequests  int `json:"web_fetch_requests"`
+}
+
+// ServerToolRequests returns the web search and web fetch counts.
+func (u Usage) ServerToolRequests() (webSearch, webFetch int) {
+	if u.ServerToolUse != nil {
+		webSearch = u.ServerToolUse.WebSearchRequests
+		webFetch = u.ServerToolUse.WebFetchRequests
+	}
+	return
+}
+
+// CacheWriteTokens splits cache writes into 5-minute and 1-hour tokens.
+// Logs without the split report only a total, which counts as 5-minute.
+func (u Usage) CacheWriteTokens() (cache5m, cache1h int) {
+	if u.CacheCreation != nil {
+		cache5m = u.CacheCreation.Ephemeral5mInputTokens
+		cache1h = u.CacheCreation.Ephemeral1hInputTokens
+	}
+	if cache5m == 0 && cache1h == 0 && u.CacheCreationInputTokens > 0 {
+		cache5m = u.CacheCreationInputTokens
+	}
+	return
+}
+
+// TotalInputTokens is the request's whole prompt: uncached input plus cache
+// reads and writes. Threshold tiers are chosen by it.
+func (u Usage) TotalInputTokens() int {
+	cache5m, cache1h := u.CacheWriteTokens()
+	return u.InputTokens + u.CacheReadInputTokens + cache5m + cache1h
+}
+
+// isLongContext reports whether a request is billed at a threshold tier.
+func isLongContext(model string, usage Usage) bool {
+	_, tiered := ResolvePricing(model).ForInput(usage.TotalInputTokens())
+	return tiered
+}
+
+// CalcCost returns the cost in dollars of one request to model, applying
+// its service tier, long-context tier and server tool fees.
+func CalcCost(model string, usage Usage) float64 {
+	pricing := ResolvePricing(model)
+	p, _ := pricing.ForInput(usage.TotalInputTokens())
+	const mtok = 1_000_000.0
+	cache5m, cache1h := usage.CacheWriteTokens()
+
+	webSearch, webFetch := usage.ServerToolRequests()
+
+	tokens := (float64(usage.InputTokens)/mtok)*p.Input +
+		(float64(usage.OutputTokens)/mtok)*p.Output +
+		(float64(cache5m)/mtok)*p.CacheWrite5m() +
+		(float64(cache1h)/mtok)*p.CacheWrite1h() +
+		(float64(usage.CacheReadInputTokens)/mtok)*p.CacheRead()
+	return tokens*pricing.TierMultiplier(usage.ServiceTier) +
+		(float64(webSearch)/1000)*serverToolPricing["web_search"] +
+		(float64(webFetch)/1000)*serverToolPricing["web_fetch"]
+}
+
+// ModelName returns a model's display name, such as "Opus 4.6", or the id
+// itself when it is not a known family.
+func ModelName(model string) string {
+	m := strings.TrimPrefix(strings.ToLower(model), "claude-")
+	switch {
+	case strings.HasPrefix(m, "opus-4-6"):
+		return "Opus 4.6"
+	case strings.HasPrefix(m, "opus-4-5"):
+		return "Opus 4.5"
+	case strings.HasPrefix(m, "opus-4-1"):
+		return "Opus 4.1"
+	case strings.HasPrefix(m, "opus-4"):
+		return "Opus 4"
+	case strings.HasPrefix(m, "opus-3"):
+		return "Opus 3"
+	case strings.HasPrefix(m, "sonnet-4-6"):
+		return "Sonnet 4.6"
+	case strings.HasPrefix(m, "sonnet-4-5"):
+		return "Sonnet 4.5"
+	case strings.HasPrefix(m, "sonnet-4"):
+		return "Sonnet 4"
+	case strings.HasPrefix(m, "sonnet-3"):
+		return "Sonnet 3.x"
+	case strings.HasPrefix(m, "haiku-4-5"):
+		return "Haiku 4.5"
+	case strings.HasPrefix(m, "haiku-3-5"):
+		return "Haiku 3.5"
+	case strings.HasPrefix(m, "haiku-3"):
+		return "Haiku 3"
+	default:
+		return model
+	}
+}
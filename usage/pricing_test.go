@@ -0,0 +1,167 @@
+package usage
+
+import (
+	"testing"
+)
+
+func TestResolvePricingExactMatch(t *testing.T) {
+	p := ResolvePricing("claude-opus-4-6")
+	if p.Input != 5.0 {
+		t.Errorf("expected input=5.0, got %f", p.Input)
+	}
+	if p.Output != 25.0 {
+		t.Errorf("expected output=25.0, got %f", p.Output)
+	}
+}
+
+func TestResolvePricingFamilyPrefix(t *testing.T) {
+	p := ResolvePricing("claude-opus-4-5-20260101")
+	if p.Input != 5.0 {
+		t.Errorf("expected input=5.0 (opus-4-5 pricing), got %f", p.Input)
+	}
+}
+
+func TestResolvePricingFallback(t *testing.T) {
+	p := ResolvePricing("claude-unknown-99")
+	if p.Input != 3.0 {
+		t.Errorf("expected fallback input=3.0, got %f", p.Input)
+	}
+}
+
+func TestCalcCostBasic(t *testing.T) {
+	usage := Usage{
+		InputTokens:              1_000_000,
+		OutputTokens:             1_000_000,
+		CacheReadInputTokens:     0,
+		CacheCreationInputTokens: 0,
+	}
+	cost := CalcCost("claude-opus-4-6", usage)
+	assertCost(t, "basic opus cost", cost, 30.0)
+}
+
+func TestCalcCostWithCache(t *testing.T) {
+	usage := Usage{
+		InputTokens:              0,
+		OutputTokens:             0,
+		CacheReadInputTokens:     1_000_000,
+		CacheCreationInputTokens: 1_000_000,
+	}
+	cost := CalcCost("claude-opus-4-6", usage)
+	assertCost(t, "cache cost", cost, 6.75)
+}
+
+func TestCalcCostWithCacheBreakdown(t *testing.T) {
+	usage := Usage{
+		InputTokens:              0,
+		OutputTokens:             0,
+		CacheReadInputTokens:     0,
+		CacheCreationInputTokens: 2_000_000,
+		CacheCreation: &CacheCreation{
+			Ephemeral5mInputTokens: 1_000_000,
+			Ephemeral1hInputTokens: 1_000_000,
+		},
+	}
+	cost := CalcCost("claude-opus-4-6", usage)
+	assertCost(t, "cache breakdown cost", cost, 16.25)
+}
+
+func TestCalcCostServiceTier(t *testing.T) {
+	usage := Usage{
+		InputTokens:          1_000_000,
+		OutputTokens:         1_000_000,
+		CacheReadInputTokens: 1_000_000,
+	}
+	tests := []struct {
+		tier string
+		want float64
+	}{
+		{"", 30.5},
+		{"standard", 30.5},
+		{"priority", 30.5},
+		{"batch", 15.25},
+		{"something_new", 30.5},
+	}
+	for _, tt := range tests {
+		usage.ServiceTier = tt.tier
+		assertCost(t, "tier "+tt.tier, CalcCost("claude-opus-4-6", usage), tt.want)
+	}
+}
+
+func TestCalcCostLongContext(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+		usage Usage
+		want  float64
+		long  bool
+	}{
+		// Exactly at the threshold stays on base rates: 200K * $3 + 10K * $15.
+		{"sonnet at threshold", "claude-sonnet-4-6",
+			Usage{InputTokens: 200_000, OutputTokens: 10_000}, 0.75, false},
+		// Cache reads count toward the threshold and are billed at the tier's
+		// input rate: 1K * $6 + 200K * $0.60 + 10K * $22.50.
+		{"sonnet above threshold via cache", "claude-sonnet-4-5-20250929",
+			Usage{InputTokens: 1_000, CacheReadInputTokens: 200_000, OutputTokens: 10_000}, 0.351, true},
+		{"sonnet cache writes count", "claude-sonnet-4-20250514",
+			Usage{InputTokens: 1_000, CacheCreationInputTokens: 200_000}, 1.506, true},
+		{"opus has no long-context tier", "claude-opus-4-6",
+			Usage{InputTokens: 300_000}, 1.5, false},
+		// Batch halves the long-context rates too.
+		{"sonnet long context on batch", "claude-sonnet-4-6",
+			Usage{InputTokens: 300_000, ServiceTier: "batch"}, 0.9, true},
+	}
+	for _, tt := range tests {
+		assertCost(t, tt.name, CalcCost(tt.model, tt.usage), tt.want)
+		if got := isLongContext(tt.model, tt.usage); got != tt.long {
+			t.Errorf("%s: isLongContext = %v, want %v", tt.name, got, tt.long)
+		}
+	}
+}
+
+func TestCalcCostServerTools(t *testing.T) {
+	usage := Usage{
+		InputTokens:   1_000_000,
+		ServerToolUse: &ServerToolUse{WebSearchRequests: 150, WebFetchRequests: 40},
+	}
+	// $5 input + 150 searches at $10/1000; web fetches are free
+	assertCost(t, "server tools", CalcCost("claude-opus-4-6", usage), 6.5)
+
+	usage.ServiceTier = "batch"
+	assertCost(t, "server tools are not discounted", CalcCost("claude-opus-4-6", usage), 4.0)
+}
+
+func TestShortModel(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"claude-opus-4-6", "Opus 4.6"},
+		{"claude-opus-4-5-20251101", "Opus 4.5"},
+		{"claude-opus-4-1-20250414", "Opus 4.1"},
+		{"claude-sonnet-4-6", "Sonnet 4.6"},
+		{"claude-sonnet-4-5-20250929", "Sonnet 4.5"},
+		{"claude-sonnet-4-20250514", "Sonnet 4"},
+		{"claude-haiku-4-5-20251001", "Haiku 4.5"},
+		{"claude-haiku-3-5-20241022", "Haiku 3.5"},
+		{"unknown-model", "unknown-model"},
+	}
+	for _, tt := range tests {
+		got := ModelName(tt.input)
+		if got != tt.expected {
+			t.Errorf("ModelName(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestTierMultiplier(t *testing.T) {
+	p := ModelPricing{Input: 5, Output: 25}
+	for tier, want := range map[string]float64{"standard": 1, "priority": 1, "batch": 0.5, "": 1, "flex": 1} {
+		if got := p.TierMultiplier(tier); got != want {
+			t.Errorf("TierMultiplier(%q) = %v, want %v", tier, got, want)
+		}
+	}
+	p.TierMultipliers = map[string]float64{"batch": 1}
+	if got := p.TierMultiplier("batch"); got != 1 {
+		t.Errorf("TierMultiplier(batch) with an override = %v, want 1", got)
+	}
+}
@@ -0,0 +1,78 @@
+package usage
+
+import (
+	"strings"
+)
+
+// projectSlug is the directory name Claude Code gives a project under
+// projects/: its working directory with every character other than an
+// ASCII letter or digit replaced by '-'. The mapping is lossy, which is why
+// the real path is taken from the records' cwd instead.
+func projectSlug(cwd string) string {
+	b := []byte(cwd)
+	for i, c := range b {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			b[i] = '-'
+		}
+	}
+	return string(b)
+}
+
+// preferCwd picks the working directory that best names a project: one
+// whose slug is the project's own (the directory Claude Code was started
+// in), otherwise the shortest, since sessions usually only move deeper.
+func preferCwd(slug, cur, cand string) string {
+	switch {
+	case cand == "" || cand == cur:
+		return cur
+	case cur == "":
+		return cand
+	}
+	curMatch, candMatch := projectSlug(cur) == slug, projectSlug(cand) == slug
+	if curMatch != candMatch {
+		if candMatch {
+			return cand
+		}
+		return cur
+	}
+	if len(cand) != len(cur) {
+		if len(cand) < len(cur) {
+			return cand
+		}
+		return cur
+	}
+	return min(cur, cand)
+}
+
+// projectFilter matches -project against a project's slug and, when it is
+// known, its real path. Separators are normalized, so "git/webapp" matches
+// a Windows path too.
+type projectFilter struct {
+	path string // lowercased, forward slashes
+	slug string // lowercased slug form, for matching directory names
+}
+
+func newProjectFilter(s string) projectFilter {
+	lower := strings.ToLower(s)
+	return projectFilter{
+		path: strings.ReplaceAll(lower, `\`, "/"),
+		slug: strings.ToLower(projectSlug(s)),
+	}
+}
+
+// mayMatch reports whether a project directory could match; it is a cheap
+// pre-check on the slug before any of the project's files are read.
+func (f projectFilter) mayMatch(slug string) bool {
+	return strings.Contains(strings.ToLower(slug), f.slug)
+}
+
+// matches reports whether a project matches. Without a cwd, the slug form of
+// the filter is used, so "my/app" still finds -home-bob-my-app; with one,
+// the filter must appear as given in the slug or the path.
+func (f projectFilter) matches(slug, cwd string) bool {
+	if cwd == "" {
+		return f.mayMatch(slug)
+	}
+	return strings.Contains(strings.ToLower(slug), f.path) ||
+		strings.Contains(strings.ReplaceAll(strings.ToLower(cwd), `\`, "/"), f.path)
+}
@@ -0,0 +1,70 @@
+package usage
+
+import "testing"
+
+func TestProjectSlug(t *testing.T) {
+	tests := map[string]string{
+		`C:\Users\alice\git\webapp`:  "C--Users-alice-git-webapp",
+		"/home/bob/src/my-app":       "-home-bob-src-my-app",
+		"/Users/carol/work/app.v2_x": "-Users-carol-work-app-v2-x",
+	}
+	for in, want := range tests {
+		if got := projectSlug(in); got != want {
+			t.Errorf("projectSlug(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPreferCwd(t *testing.T) {
+	slug := "-home-bob-src-app"
+	tests := []struct {
+		name      string
+		cur, cand string
+		want      string
+	}{
+		{"first seen", "", "/home/bob/src/app", "/home/bob/src/app"},
+		{"empty candidate", "/home/bob/src/app", "", "/home/bob/src/app"},
+		{"slug match wins over shorter", "/home/bob", "/home/bob/src/app", "/home/bob/src/app"},
+		{"slug match is kept", "/home/bob/src/app", "/home/bob/src/app/web", "/home/bob/src/app"},
+		{"shorter wins without a match", "/home/bob/src/app/web/ui", "/home/bob/src/app/web", "/home/bob/src/app/web"},
+		{"ties break by name", "/home/bob/src/app/b", "/home/bob/src/app/a", "/home/bob/src/app/a"},
+	}
+	for _, tt := range tests {
+		if got := preferCwd(slug, tt.cur, tt.cand); got != tt.want {
+			t.Errorf("%s: preferCwd = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProjectFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		slug   string
+		cwd    string
+		want   bool
+	}{
+		{"webapp", "C--Users-alice-git-webapp", `C:\Users\alice\git\webapp`, true},
+		{"git/webapp", "C--Users-alice-git-webapp", `C:\Users\alice\git\webapp`, true},
+		{"WEBAPP", "C--Users-alice-git-webapp", `C:\Users\alice\git\webapp`, true},
+		// The slug stays a valid filter once the path is known.
+		{"C--Users-alice-git-webapp", "C--Users-alice-git-webapp", `C:\Users\alice\git\webapp`, true},
+		{"alice-git", "C--Users-alice-git-webapp", `C:\Users\alice\git\webapp`, true},
+		{"my-app", "-home-bob-my-app", "/home/bob/my/app", true},
+		// The slug can't tell "my/app" from "my-app"; the real path can.
+		{"my/app", "-home-bob-my-app", "/home/bob/my-app", false},
+		{"my/app", "-home-bob-my-app", "/home/bob/my/app", true},
+		// Without a recorded cwd, the slug is all there is.
+		{"my/app", "-home-bob-my-app", "", true},
+		{"other", "-home-bob-my-app", "", false},
+	}
+	for _, tt := range tests {
+		f := newProjectFilter(tt.filter)
+		if got := f.matches(tt.slug, tt.cwd); got != tt.want {
+			t.Errorf("filter %q: matches(%q, %q) = %v, want %v", tt.filter, tt.slug, tt.cwd, got, tt.want)
+		}
+		// The walk's pre-check must never skip a project that matches.
+		if tt.want && !f.mayMatch(tt.slug) {
+			t.Errorf("filter %q: mayMatch(%q) = false for a matching project", tt.filter, tt.slug)
+		}
+	}
+}
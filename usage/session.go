@@ -0,0 +1,108 @@
+package usage
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SessionCost returns the cost of one conversation: the transcript at
+// transcriptPath plus the subagent transcripts stored next to it. err is set
+// when the transcript itself cannot be read; subagent transcripts that cannot
+// be read are left out of cost and listed in warnings instead.
+func SessionCost(transcriptPath string) (cost float64, warnings []error, err error) {
+	return NewParser(Options{}).SessionCost(transcriptPath)
+}
+
+// SessionCost is the package-level SessionCost, using the parse cache in
+// Options.CacheDir when the transcript lives in a projects directory. It
+// shares entries with Parse, so only what was appended since either last
+// read the transcript is parsed.
+func (p *Parser) SessionCost(transcriptPath string) (cost float64, warnings []error, err error) {
+	var cache *parseCache
+	if p.opts.CacheDir != "" {
+		cache = openCache(p.opts.CacheDir)
+	}
+	deduped, warnings, err := parseSession(transcriptPath, cache)
+	if err != nil {
+		return 0, nil, err
+	}
+	return sessionCost(deduped), warnings, nil
+}
+
+func parseSession(transcriptPath string, cache *parseCache) (records map[string]*Record, warnings []error, err error) {
+	transcriptPath, err = filepath.Abs(transcriptPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Transcripts are stored as <projects>/<slug>/<session>.jsonl.
+	root := filepath.Dir(filepath.Dir(transcriptPath))
+	if filepath.Base(root) != "projects" {
+		root = ""
+	}
+
+	records = make(map[string]*Record)
+	var cacheErr error
+	// Later files win, as in parseFiles: the subagents after the transcript.
+	read := func(path string) error {
+		f := logFile{path: path}
+		if root != "" {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			f.fileSource, f.root = sourceForPath(rel), root
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		f.size, f.modTime = info.Size(), info.ModTime()
+		data, state, updated, err := parseLogFile(f, false, cache)
+		if err != nil {
+			return err
+		}
+		if updated {
+			if err := cache.store(f, &cacheEntry{Size: f.size, ModTime: f.modTime, State: state, Data: data}); err != nil && cacheErr == nil {
+				cacheErr = err
+			}
+		}
+		maps.Copy(records, data.Records)
+		return nil
+	}
+
+	if err := read(transcriptPath); err != nil {
+		return nil, nil, fmt.Errorf("parsing transcript: %w", err)
+	}
+
+	base := strings.TrimSuffix(transcriptPath, ".jsonl")
+	subagentDir := filepath.Join(base, "subagents")
+
+	entries, err := os.ReadDir(subagentDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
+			continue
+		}
+		if err := read(filepath.Join(subagentDir, entry.Name())); err != nil {
+			warnings = append(warnings, fmt.Errorf("subagent %s: %w", entry.Name(), err))
+		}
+	}
+	if cacheErr != nil {
+		warnings = append(warnings, fmt.Errorf("writing parse cache: %w", cacheErr))
+	}
+
+	return records, warnings, nil
+}
+
+func sessionCost(deduped map[string]*Record) float64 {
+	var total float64
+	for _, r := range deduped {
+		total += CalcCost(r.Model, r.Usage)
+	}
+	return total
+}
@@ -0,0 +1,121 @@
+package usage
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSession_MainOnly(t *testing.T) {
+	dir := t.TempDir()
+	transcript := filepath.Join(dir, "session.jsonl")
+	content := makeRecord("req_1", "claude-opus-4-6", "2026-02-19T10:00:00Z", 1000, 500, 5000, 200, 100) + "\n" +
+		makeRecord("req_2", "claude-opus-4-6", "2026-02-19T10:05:00Z", 2000, 800, 3000, 0, 0) + "\n"
+	if err := os.WriteFile(transcript, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deduped, _, err := parseSession(transcript, nil)
+	if err != nil {
+		t.Fatalf("parseSession: %v", err)
+	}
+	if len(deduped) != 2 {
+		t.Errorf("got %d records, want 2", len(deduped))
+	}
+
+	cost := sessionCost(deduped)
+	if cost <= 0 {
+		t.Errorf("expected positive cost, got %f", cost)
+	}
+}
+
+func TestParseSession_WithSubagents(t *testing.T) {
+	dir := t.TempDir()
+	transcript := filepath.Join(dir, "abc123.jsonl")
+	mainContent := makeRecord("req_main", "claude-opus-4-6", "2026-02-19T10:00:00Z", 1000, 500, 5000, 0, 0) + "\n"
+	if err := os.WriteFile(transcript, []byte(mainContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	subDir := filepath.Join(dir, "abc123", "subagents")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	subContent := makeRecord("req_sub", "claude-haiku-4-5-20251001", "2026-02-19T10:01:00Z", 500, 200, 1000, 0, 0) + "\n"
+	if err := os.WriteFile(filepath.Join(subDir, "agent-a1b2c3d.jsonl"), []byte(subContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deduped, _, err := parseSession(transcript, nil)
+	if err != nil {
+		t.Fatalf("parseSession: %v", err)
+	}
+	if len(deduped) != 2 {
+		t.Errorf("got %d records, want 2 (main + subagent)", len(deduped))
+	}
+}
+
+func TestParseSession_MissingSubagentDir(t *testing.T) {
+	dir := t.TempDir()
+	transcript := filepath.Join(dir, "session.jsonl")
+	content := makeRecord("req_1", "claude-opus-4-6", "2026-02-19T10:00:00Z", 1000, 500, 0, 0, 0) + "\n"
+	if err := os.WriteFile(transcript, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deduped, _, err := parseSession(transcript, nil)
+	if err != nil {
+		t.Fatalf("parseSession: %v", err)
+	}
+	if len(deduped) != 1 {
+		t.Errorf("got %d records, want 1", len(deduped))
+	}
+}
+
+func TestParseSession_MissingTranscript(t *testing.T) {
+	_, _, err := parseSession("/nonexistent/path/session.jsonl", nil)
+	if err == nil {
+		t.Error("expected error for missing transcript")
+	}
+}
+
+func TestSessionCost(t *testing.T) {
+	deduped := map[string]*Record{
+		"req_1": {
+			Model: "claude-opus-4-6",
+			Usage: Usage{InputTokens: 1000, OutputTokens: 500},
+		},
+		"req_2": {
+			Model: "claude-opus-4-6",
+			Usage: Usage{InputTokens: 2000, OutputTokens: 1000},
+		},
+	}
+
+	// Opus 4.6: Input=$5/M, Output=$25/M
+	// req_1: (1000/1M)*5 + (500/1M)*25 = 0.005 + 0.0125 = 0.0175
+	// req_2: (2000/1M)*5 + (1000/1M)*25 = 0.01 + 0.025 = 0.035
+	// Total: 0.0525
+	cost := sessionCost(deduped)
+	if math.Abs(cost-0.0525) > 0.000001 {
+		t.Errorf("sessionCost = %.6f, want 0.052500", cost)
+	}
+}
+
+func TestParseSession_Fixture(t *testing.T) {
+	transcriptPath := filepath.Join("testdata", "projects", "C--Users-alice-git-webapp", "abc123.jsonl")
+	deduped, _, err := parseSession(transcriptPath, nil)
+	if err != nil {
+		t.Fatalf("parseSession: %v", err)
+	}
+
+	if len(deduped) != 7 {
+		t.Errorf("got %d deduped records, want 7", len(deduped))
+	}
+
+	cost := sessionCost(deduped)
+	// Opus: 1.2335 + Haiku: 0.057625 = 1.291125
+	if math.Abs(cost-1.291125) > 0.000001 {
+		t.Errorf("sessionCost = %.6f, want 1.291125", cost)
+	}
+}
@@ -0,0 +1,34 @@
+package usage
+
+import "path/filepath"
+
+// Source is one Claude Code data directory to read, such as a second
+// CLAUDE_CONFIG_DIR or a copy of ~/.claude from another machine.
+type Source struct {
+	Label      string // names the source in the per-source breakdown
+	BaseDir    string
+	ArchiveDir string // also read logs archived here by `goccc archive`; "" for none
+}
+
+// sources returns the directories to read: Sources when set, otherwise a
+// single unlabeled source made of BaseDir and ArchiveDir. A base directory
+// listed more than once is kept only the first time.
+func (o Options) sources() []Source {
+	if len(o.Sources) == 0 {
+		return []Source{{BaseDir: o.BaseDir, ArchiveDir: o.ArchiveDir}}
+	}
+	var sources []Source
+	seen := make(map[string]bool)
+	for _, src := range o.Sources {
+		dir := filepath.Clean(src.BaseDir)
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		sources = append(sources, src)
+	}
+	return sources
+}
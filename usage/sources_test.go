@@ -0,0 +1,72 @@
+package usage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMultipleSources(t *testing.T) {
+	shared := makeRecord("req_shared", "claude-opus-4-6", ts(0, 9), 100, 50, 0, 0, 0)
+	desktop := setupProject(t, "proj-a", []string{
+		shared,
+		makeRecord("req_desktop", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	laptop := setupProject(t, "proj-b", []string{
+		shared,
+		makeRecord("req_laptop", "claude-haiku-4-5", ts(0, 11), 100, 50, 0, 0, 0),
+		makeRecord("req_laptop2", "claude-haiku-4-5", ts(0, 12), 100, 50, 0, 0, 0),
+	})
+
+	data, err := parseLogs(Options{Sources: []Source{
+		{Label: "desktop", BaseDir: desktop},
+		{Label: "laptop", BaseDir: laptop},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertInt(t, "TotalFiles", data.TotalFiles, 2)
+	assertInt(t, "TotalRecords", data.TotalRecords, 4)
+	// A request found in several sources counts toward the last one.
+	assertInt(t, "desktop requests", data.SourceUsage["desktop"].Requests, 1)
+	assertInt(t, "laptop requests", data.SourceUsage["laptop"].Requests, 3)
+	if len(data.ProjectUsage) != 2 {
+		t.Errorf("got %d projects, want 2", len(data.ProjectUsage))
+	}
+}
+
+func TestMultipleSources_MissingDir(t *testing.T) {
+	base := setupProject(t, "proj", []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+	})
+	_, err := parseLogs(Options{Sources: []Source{
+		{Label: "a", BaseDir: base},
+		{Label: "b", BaseDir: filepath.Join(base, "missing")},
+	}})
+	if err == nil {
+		t.Error("expected an error for a source without a projects directory")
+	}
+}
+
+func TestMultipleSources_SameDir(t *testing.T) {
+	base := setupProject(t, "proj", []string{
+		makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 100, 50, 0, 0, 0),
+		makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 100, 50, 0, 0, 0),
+	})
+	opts := Options{
+		Sources:  []Source{{Label: "a", BaseDir: base}, {Label: "b", BaseDir: base + string(filepath.Separator)}},
+		Jobs:     4,
+		CacheDir: t.TempDir(),
+	}
+	// The second run reads the records back from the cache.
+	for run := range 2 {
+		data, err := parseLogs(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertInt(t, "TotalFiles", data.TotalFiles, 1)
+		assertInt(t, "TotalRecords", data.TotalRecords, 2)
+		if len(data.Sources) != 1 || data.SourceUsage["a"] == nil {
+			t.Errorf("run %d: Sources = %v, want only the first listing", run, data.Sources)
+		}
+	}
+}
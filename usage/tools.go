@@ -0,0 +1,106 @@
+package usage
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ToolUsage aggregates the tool_use blocks of assistant messages.
+type ToolUsage struct {
+	Calls    int // tool_use blocks
+	Requests int // requests with at least one call to the tool
+	// Cost is the tool's share of the cost of the requests that called it,
+	// split evenly across each request's tool calls.
+	Cost float64
+}
+
+// ToolUse is a tool_use content block of an assistant message.
+type ToolUse struct {
+	ID   string
+	Name string
+}
+
+func parseToolUses(line []byte) []ToolUse {
+	var rec struct {
+		Message struct {
+			Content []contentBlock `json:"content"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return nil
+	}
+	var tools []ToolUse
+	for _, c := range rec.Message.Content {
+		if c.Type == "tool_use" && c.Name != "" {
+			tools = append(tools, ToolUse{ID: c.ID, Name: c.Name})
+		}
+	}
+	return tools
+}
+
+// mergeToolUses returns the union of two entries' tool calls, by tool_use id.
+func mergeToolUses(prev, next []ToolUse) []ToolUse {
+	if len(prev) == 0 {
+		return next
+	}
+	merged := append([]ToolUse(nil), prev...)
+	for _, t := range next {
+		dup := false
+		for _, p := range prev {
+			if p.ID == t.ID {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
+
+// addToolUsage attributes a request's cost to the tools it called, and to
+// the MCP servers providing them.
+func addToolUsage(byTool, byServer map[string]*ToolUsage, tools []ToolUse, cost float64) {
+	if len(tools) == 0 {
+		getOrCreateToolUsage(byTool, "").add(0, true, cost)
+		return
+	}
+	share := cost / float64(len(tools))
+	seen := make(map[string]bool, len(tools))
+	for _, t := range tools {
+		getOrCreateToolUsage(byTool, t.Name).add(1, !seen[t.Name], share)
+		seen[t.Name] = true
+		if server, _, ok := MCPServer(t.Name); ok {
+			getOrCreateToolUsage(byServer, server).add(1, !seen["mcp__"+server], share)
+			seen["mcp__"+server] = true
+		}
+	}
+}
+
+func (u *ToolUsage) add(calls int, newRequest bool, cost float64) {
+	u.Calls += calls
+	if newRequest {
+		u.Requests++
+	}
+	u.Cost += cost
+}
+
+func getOrCreateToolUsage(m map[string]*ToolUsage, name string) *ToolUsage {
+	if u, ok := m[name]; ok {
+		return u
+	}
+	u := &ToolUsage{}
+	m[name] = u
+	return u
+}
+
+// MCPServer splits an MCP tool name of the form mcp__<server>__<tool>.
+func MCPServer(name string) (server, tool string, ok bool) {
+	rest, found := strings.CutPrefix(name, "mcp__")
+	if !found {
+		return "", "", false
+	}
+	server, tool, ok = strings.Cut(rest, "__")
+	return server, tool, ok && server != ""
+}
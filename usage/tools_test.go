@@ -0,0 +1,107 @@
+package usage
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// withToolUses puts tool_use blocks with the given ids and names into an
+// assistant record built by makeRecord.
+func withToolUses(line string, idNames ...string) string {
+	var blocks []string
+	for i := 0; i+1 < len(idNames); i += 2 {
+		blocks = append(blocks, fmt.Sprintf(`{"type":"tool_use","id":%q,"name":%q,"input":{}}`, idNames[i], idNames[i+1]))
+	}
+	return strings.Replace(line, `"role":"assistant",`, `"role":"assistant","content":[`+strings.Join(blocks, ",")+`],`, 1)
+}
+
+func TestMCPServer(t *testing.T) {
+	tests := []struct {
+		name         string
+		server, tool string
+		ok           bool
+	}{
+		{"mcp__github__create_issue", "github", "create_issue", true},
+		{"mcp__claude-in-chrome__navigate", "claude-in-chrome", "navigate", true},
+		{"Bash", "", "", false},
+		{"mcp__broken", "broken", "", false},
+	}
+	for _, tt := range tests {
+		server, tool, ok := MCPServer(tt.name)
+		if server != tt.server || tool != tt.tool || ok != tt.ok {
+			t.Errorf("MCPServer(%q) = %q, %q, %v; want %q, %q, %v", tt.name, server, tool, ok, tt.server, tt.tool, tt.ok)
+		}
+	}
+}
+
+func TestToolUsage_StreamingEntriesAndCostSplit(t *testing.T) {
+	// One request streamed as two entries, each carrying one tool call.
+	// Opus 4.6: 1000 input + 1000 output = $0.03.
+	base := setupProject(t, "test-project", []string{
+		withToolUses(makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 1000, 500, 0, 0, 0), "toolu_1", "Bash"),
+		withToolUses(makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 1000, 1000, 0, 0, 0), "toolu_2", "mcp__github__create_issue"),
+		withToolUses(makeRecord("req_2", "claude-opus-4-6", ts(0, 11), 1000, 1000, 0, 0, 0),
+			"toolu_3", "mcp__github__list_prs", "toolu_4", "mcp__github__list_prs"),
+		makeRecord("req_3", "claude-opus-4-6", ts(0, 12), 1000, 1000, 0, 0, 0),
+	})
+
+	data, err := parseLogs(Options{BaseDir: base, Tools: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bash := data.ToolUsage["Bash"]
+	if bash == nil {
+		t.Fatal("missing Bash usage")
+	}
+	assertInt(t, "Bash.Calls", bash.Calls, 1)
+	assertCost(t, "Bash.Cost", bash.Cost, 0.015)
+
+	prs := data.ToolUsage["mcp__github__list_prs"]
+	assertInt(t, "list_prs.Calls", prs.Calls, 2)
+	assertInt(t, "list_prs.Requests", prs.Requests, 1)
+	assertCost(t, "list_prs.Cost", prs.Cost, 0.03)
+
+	github := data.MCPServerUsage["github"]
+	if github == nil {
+		t.Fatal("missing github server usage")
+	}
+	assertInt(t, "github.Calls", github.Calls, 3)
+	assertInt(t, "github.Requests", github.Requests, 2)
+	assertCost(t, "github.Cost", github.Cost, 0.045)
+
+	none := data.ToolUsage[""]
+	assertInt(t, "no-tools.Requests", none.Requests, 1)
+	assertCost(t, "no-tools.Cost", none.Cost, 0.03)
+
+	var attributed float64
+	for _, u := range data.ToolUsage {
+		attributed += u.Cost
+	}
+	assertCost(t, "attributed cost", attributed, data.Totals().Cost)
+}
+
+func TestToolUsage_OffByDefault(t *testing.T) {
+	base := setupProject(t, "test-project", []string{
+		withToolUses(makeRecord("req_1", "claude-opus-4-6", ts(0, 10), 1000, 500, 0, 0, 0), "toolu_1", "Bash"),
+	})
+	cacheDir := t.TempDir()
+
+	data, err := parseLogs(Options{BaseDir: base, CacheDir: cacheDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.ToolUsage) != 0 {
+		t.Errorf("ToolUsage populated without Tools option: %v", data.ToolUsage)
+	}
+
+	// A cache written without tool calls must not be reused for a tools report.
+	data, err = parseLogs(Options{BaseDir: base, CacheDir: cacheDir, Tools: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := data.ToolUsage["Bash"]; u == nil || u.Calls != 1 {
+		t.Errorf("Bash usage after cached run = %+v, want 1 call", u)
+	}
+}
@@ -0,0 +1,167 @@
+package usage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// watchedFile is a log file followed by a Watcher. Its state carries the
+// offset parsing stopped at, so each poll only reads what was appended.
+type watchedFile struct {
+	src     fileSource
+	order   int
+	size    int64
+	modTime time.Time
+	state   fileState
+	data    *fileData
+}
+
+// Watcher follows the log files that were active today and keeps their
+// records, polling rather than relying on an OS file notifier.
+type Watcher struct {
+	dirs   []string // projects directories
+	clock  Clock
+	filter recordFilter
+	branch string // glob, as in Options.BranchFilter
+	files  map[string]*watchedFile
+	opened int // files ever followed, for walk order
+}
+
+// NewWatcher returns a Watcher over the projects directories of the sources
+// in opts, applying the same project, model and branch filters as Parse.
+func NewWatcher(opts Options) (*Watcher, error) {
+	w := &Watcher{
+		clock:  opts.clock(),
+		filter: newRecordFilter(opts),
+		branch: opts.BranchFilter,
+		files:  make(map[string]*watchedFile),
+	}
+	for _, src := range opts.sources() {
+		dir := filepath.Join(src.BaseDir, "projects")
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("no projects directory found at %s", dir)
+		}
+		w.dirs = append(w.dirs, dir)
+	}
+	return w, nil
+}
+
+// Poll picks up files modified since the start of today or within the last
+// hour, and parses whatever was appended to them since the last poll. A
+// partially written trailing line is read again on the next poll, once it
+// is complete. Files that were deleted or have gone quiet since are
+// dropped. Files that cannot be read are skipped and reported in the
+// returned error.
+func (w *Watcher) Poll(now time.Time) error {
+	var errs []error
+	since := w.clock.startOfDay(now)
+	if hourAgo := now.Add(-time.Hour); hourAgo.Before(since) {
+		since = hourAgo
+	}
+	seen := make(map[string]bool)
+	for _, dir := range w.dirs {
+		_ = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || filepath.Ext(p) != ".jsonl" {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil || info.ModTime().Before(since) {
+				return nil
+			}
+			seen[p] = true
+			f := w.files[p]
+			if f == nil {
+				rel, err := filepath.Rel(dir, p)
+				if err != nil {
+					return nil
+				}
+				f = &watchedFile{src: sourceForPath(rel), order: w.opened, data: newFileData()}
+				w.opened++
+				w.files[p] = f
+			}
+			switch {
+			case info.Size() == f.size && info.ModTime().Equal(f.modTime):
+				return nil
+			case info.Size() < f.state.Offset, info.Size() == f.size:
+				// Truncated, or rewritten without growing: start over.
+				f.state, f.data = fileState{}, newFileData()
+			}
+			f.size, f.modTime = info.Size(), info.ModTime()
+			if err := parseFile(p, f.src, &f.state, f.data); err != nil {
+				errs = append(errs, fmt.Errorf("could not read %s: %w", p, err))
+			}
+			return nil
+		})
+	}
+	for p := range w.files {
+		if !seen[p] {
+			delete(w.files, p)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WatchTotals are the live totals reported by a Watcher.
+type WatchTotals struct {
+	Today    Bucket
+	LastHour Bucket
+	Session  Bucket
+	// The current session is the one with the most recent request.
+	SessionID string
+	Project   string // project slug of the current session
+	Cwd       string // its working directory, when known
+	Last      *Record
+}
+
+// Totals returns the totals of everything polled so far, as of now.
+func (w *Watcher) Totals(now time.Time) WatchTotals {
+	deduped := make(map[string]*Record)
+	cwds := make(map[string]string)
+	for _, f := range w.files {
+		cwds[f.src.Project] = preferCwd(f.src.Project, cwds[f.src.Project], f.data.Cwd)
+		for id, r := range f.data.Records {
+			r.order = f.order
+			mergeRecord(deduped, id, r)
+		}
+	}
+
+	today, hourAgo := w.clock.startOfDay(now), now.Add(-time.Hour)
+	var t WatchTotals
+	var kept []*Record
+	for _, r := range deduped {
+		if !w.filter.keepProject(r.Project, cwds[r.Project]) || !w.filter.keepModel(r.Model) {
+			continue
+		}
+		if w.branch != "" {
+			if ok, _ := path.Match(w.branch, r.Branch); !ok {
+				continue
+			}
+		}
+		kept = append(kept, r)
+		if t.Last == nil || r.Timestamp.After(t.Last.Timestamp) {
+			t.Last = r
+		}
+	}
+	if t.Last != nil {
+		t.SessionID, t.Project = t.Last.Session, t.Last.Project
+		t.Cwd = cwds[t.Project]
+	}
+	for _, r := range kept {
+		b := r.Bucket()
+		if !r.Timestamp.Before(today) {
+			t.Today.add(&b)
+		}
+		if !r.Timestamp.Before(hourAgo) {
+			t.LastHour.add(&b)
+		}
+		if t.Last != nil && r.Session == t.Last.Session {
+			t.Session.add(&b)
+		}
+	}
+	return t
+}
@@ -0,0 +1,137 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher_FollowsAppends(t *testing.T) {
+	now := time.Now()
+	at := func(ago time.Duration) string { return now.Add(-ago).UTC().Format(time.RFC3339) }
+	base := setupProject(t, "test-project", []string{
+		makeRecord("req_old", "claude-opus-4-6", at(90*time.Minute), 100, 50, 0, 0, 0),
+	})
+	path := filepath.Join(base, "projects", "test-project", "session.jsonl")
+
+	w, err := NewWatcher(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Poll(now)
+	tot := w.Totals(now)
+	assertInt(t, "LastHour requests", tot.LastHour.Requests, 0)
+	assertInt(t, "Session requests", tot.Session.Requests, 1)
+
+	// A complete record and one still being written.
+	partial := makeRecord("req_new2", "claude-opus-4-6", at(time.Minute), 100, 50, 0, 0, 0)
+	appendLines(t, path,
+		makeRecord("req_new", "claude-opus-4-6", at(5*time.Minute), 100, 50, 0, 0, 0)+"\n",
+		partial[:40])
+	w.Poll(now)
+	tot = w.Totals(now)
+	assertInt(t, "LastHour after append", tot.LastHour.Requests, 1)
+	assertInt(t, "Session after append", tot.Session.Requests, 2)
+
+	appendLines(t, path, partial[40:]+"\n")
+	w.Poll(now)
+	tot = w.Totals(now)
+	assertInt(t, "LastHour after completed line", tot.LastHour.Requests, 2)
+	assertInt(t, "Session after completed line", tot.Session.Requests, 3)
+	if tot.Last == nil || tot.Last.Usage.InputTokens != 100 {
+		t.Errorf("Last = %+v, want req_new2", tot.Last)
+	}
+	if d := w.files[path].state.Issues; len(d) != 0 {
+		t.Errorf("partial line reported as malformed: %+v", d)
+	}
+}
+
+func TestWatcher_CurrentSessionAndToday(t *testing.T) {
+	now := time.Now()
+	at := func(ago time.Duration) string { return now.Add(-ago).UTC().Format(time.RFC3339) }
+	base := setupProject(t, "proj-a", []string{
+		makeRecord("req_a", "claude-opus-4-6", at(3*time.Minute), 100, 50, 0, 0, 0),
+	})
+	addProject(t, base, "proj-b", nil)
+	other := filepath.Join(base, "projects", "proj-b", "other.jsonl")
+	writeTranscript(t, other,
+		makeRecord("req_b1", "claude-opus-4-6", at(2*time.Minute), 100, 50, 0, 0, 0),
+		makeRecord("req_b2", "claude-opus-4-6", at(time.Minute), 100, 50, 0, 0, 0),
+		makeRecord("req_yesterday", "claude-opus-4-6", at(48*time.Hour), 100, 50, 0, 0, 0))
+
+	w, err := NewWatcher(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Poll(now)
+	tot := w.Totals(now)
+	if tot.SessionID != "other" {
+		t.Errorf("SessionID = %q, want other (most recent request)", tot.SessionID)
+	}
+	assertInt(t, "Session requests", tot.Session.Requests, 3)
+	assertInt(t, "LastHour requests", tot.LastHour.Requests, 3)
+	// Shortly after midnight, some of the last few minutes fall on yesterday.
+	wantToday := 0
+	for _, ago := range []time.Duration{3 * time.Minute, 2 * time.Minute, time.Minute} {
+		if !now.Add(-ago).Before(localMidnight()) {
+			wantToday++
+		}
+	}
+	assertInt(t, "Today requests", tot.Today.Requests, wantToday)
+
+	w, err = NewWatcher(Options{BaseDir: base, ProjectFilter: "proj-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Poll(now)
+	tot = w.Totals(now)
+	assertInt(t, "LastHour with -project", tot.LastHour.Requests, 1)
+
+}
+
+func TestWatcher_ReplacedAndDeletedFiles(t *testing.T) {
+	now := time.Now()
+	at := func(ago time.Duration) string { return now.Add(-ago).UTC().Format(time.RFC3339) }
+	base := setupProject(t, "test-project", []string{
+		makeRecord("req_a", "claude-opus-4-6", at(time.Minute), 100, 50, 0, 0, 0),
+	})
+	path := filepath.Join(base, "projects", "test-project", "session.jsonl")
+
+	w, err := NewWatcher(Options{BaseDir: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Poll(now); err != nil {
+		t.Fatal(err)
+	}
+
+	// Same size, new content: only the modification time tells.
+	line := makeRecord("req_b", "claude-opus-4-6", at(time.Minute), 100, 50, 0, 0, 0) + "\n"
+	if err := os.WriteFile(path, []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := now.Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Poll(now); err != nil {
+		t.Fatal(err)
+	}
+	tot := w.Totals(now)
+	assertInt(t, "Session requests after replace", tot.Session.Requests, 1)
+	if _, ok := w.files[path].data.Records["req_b"]; !ok {
+		t.Error("replaced file was not read again")
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Poll(now); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.files) != 0 {
+		t.Errorf("files = %d, want deleted files dropped", len(w.files))
+	}
+	assertInt(t, "Session requests after delete", w.Totals(now).Session.Requests, 0)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/backstabslash/goccc/usage"
+	"github.com/fatih/color"
+)
+
+func formatWatch(t usage.WatchTotals, now time.Time, loc *time.Location) string {
+	var sb strings.Builder
+	bold := color.New(color.Bold)
+	dim := color.New(color.Faint)
+
+	sb.WriteString(bold.Sprintln("═══════════════════════════════════════════════════════════════════════════════"))
+	sb.WriteString(bold.Sprintln("  Claude Code Usage — live"))
+	sb.WriteString(bold.Sprintln("═══════════════════════════════════════════════════════════════════════════════"))
+	sb.WriteString(dim.Sprintf("  Updated %s · Ctrl-C to stop\n\n", now.In(loc).Format("15:04:05")))
+
+	row := func(label string, b usage.Bucket, note string) {
+		fmt.Fprintf(&sb, "  %-10s %s %6d reqs %9s in %9s out", label, colorCost(b.Cost, 10),
+			b.Requests, fmtTokens(b.InputTokens+b.CacheRead+b.TotalCacheWrite()), fmtTokens(b.OutputTokens))
+		if note != "" {
+			sb.WriteString(dim.Sprintf("  %s", note))
+		}
+		sb.WriteString("\n")
+	}
+	row("Today", t.Today, "")
+	session := ""
+	if t.SessionID != "" {
+		project := shortProject(t.Project)
+		if t.Cwd != "" {
+			project = shortPath(t.Cwd)
+		}
+		session = shortSession(t.SessionID) + " · " + project
+	}
+	row("Session", t.Session, session)
+	row("Last hour", t.LastHour, "")
+
+	if t.Last != nil {
+		fmt.Fprintf(&sb, "\n  Last request: %s, %s, ", usage.ModelName(t.Last.Model), fmtCost(t.Last.Bucket().Cost))
+		if t.Last.Timestamp.IsZero() {
+			sb.WriteString("time unknown\n")
+		} else {
+			fmt.Fprintf(&sb, "%s ago\n", now.Sub(t.Last.Timestamp).Truncate(time.Second))
+		}
+	} else {
+		sb.WriteString(dim.Sprintln("\n  No requests today yet."))
+	}
+	return sb.String()
+}
+
+// watchUnsupportedFlags have no effect in -watch, which always follows the
+// live projects directories one poll at a time, without the parse cache,
+// and shows fixed windows: today, the current session and the last hour.
+var watchUnsupportedFlags = []string{
+	"f", "jobs", "j", "no-cache", "include-archive", "archive-dir",
+	"days", "d", "since", "until", "json", "records", "diagnostics", "strict",
+}
+
+// unsupportedWatchFlags returns the flags among set that -watch rejects, as
+// they would be typed. Files given as arguments count as -f.
+func unsupportedWatchFlags(set []string, files int) []string {
+	var bad []string
+	for _, name := range set {
+		if slices.Contains(watchUnsupportedFlags, name) {
+			bad = append(bad, "-"+name)
+		}
+	}
+	if files > 0 && !slices.Contains(bad, "-f") {
+		bad = append(bad, "-f")
+	}
+	return bad
+}
+
+// runWatch redraws live totals every interval until interrupted.
+func runWatch(opts usage.Options, interval time.Duration) {
+	w, err := usage.NewWatcher(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		pollErr := w.Poll(now)
+		// Move home and clear the screen before each redraw.
+		fmt.Print("\033[H\033[2J" + formatWatch(w.Totals(now), now, location(opts.Location)))
+		if pollErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", pollErr)
+		}
+		select {
+		case <-interrupt:
+			fmt.Println()
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/backstabslash/goccc/usage"
+)
+
+func TestFormatWatch(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	last := &usage.Record{
+		Model:     "claude-opus-4-6",
+		Project:   "-home-me-proj-a",
+		Session:   "0123456789abcdef",
+		Timestamp: now.Add(-90 * time.Second),
+		Usage:     usage.Usage{InputTokens: 100, OutputTokens: 50},
+	}
+	b := last.Bucket()
+	tot := usage.WatchTotals{Today: b, LastHour: b, Session: b, SessionID: last.Session, Project: last.Project, Cwd: "/home/me/proj-a", Last: last}
+
+	out := formatWatch(tot, now, time.UTC)
+	for _, want := range []string{"Today", "Session", "Last hour", "Last request: Opus 4.6", "1m30s ago", "proj-a"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	out = formatWatch(usage.WatchTotals{}, now, time.UTC)
+	if !strings.Contains(out, "No requests today yet.") {
+		t.Errorf("output missing the empty state:\n%s", out)
+	}
+}
+
+func TestUnsupportedWatchFlags(t *testing.T) {
+	if bad := unsupportedWatchFlags([]string{"watch", "project", "interval", "tz"}, 0); len(bad) != 0 {
+		t.Errorf("supported flags rejected: %v", bad)
+	}
+	bad := unsupportedWatchFlags([]string{"watch", "j", "since", "f"}, 2)
+	if got := strings.Join(bad, " "); got != "-j -since -f" {
+		t.Errorf("unsupportedWatchFlags = %q, want -j -since -f", got)
+	}
+	if bad := unsupportedWatchFlags([]string{"watch"}, 1); len(bad) != 1 || bad[0] != "-f" {
+		t.Errorf("file arguments: %v, want -f", bad)
+	}
+}